@@ -0,0 +1,111 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/sashko-guz/mage/internal/pkg/logger"
+	"github.com/sashko-guz/mage/internal/storage"
+)
+
+// CacheManager is implemented by storage that supports cache invalidation
+type CacheManager interface {
+	PurgeSource(sourcePath string) storage.PurgeResult
+	Flush(cacheType string) error
+}
+
+// PurgeResponse is returned by the purge endpoint
+type PurgeResponse struct {
+	Path   string              `json:"path"`
+	Purged storage.PurgeResult `json:"purged"`
+}
+
+// FlushResponse is returned by the flush endpoint
+type FlushResponse struct {
+	Type string `json:"type"`
+}
+
+// ErrorResponse is returned when an admin request fails
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// Handler handles cache administration HTTP endpoints
+type Handler struct {
+	cache CacheManager
+	token string
+}
+
+// NewHandler creates a new admin handler protected by the given bearer token
+func NewHandler(cache CacheManager, token string) *Handler {
+	return &Handler{
+		cache: cache,
+		token: token,
+	}
+}
+
+// Purge handles /admin/cache/purge endpoint
+// Removes the source image given by the "path" query parameter and all thumbnails derived from it
+func (h *Handler) Purge(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+
+	sourcePath := strings.TrimPrefix(r.URL.Query().Get("path"), "/")
+	if sourcePath == "" {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "path query parameter is required"})
+		return
+	}
+
+	result := h.cache.PurgeSource(sourcePath)
+	writeJSON(w, http.StatusOK, PurgeResponse{Path: sourcePath, Purged: result})
+}
+
+// Flush handles /admin/cache/flush endpoint
+// Clears every entry of the cache type given by the "type" query parameter
+func (h *Handler) Flush(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+
+	cacheType := r.URL.Query().Get("type")
+	if cacheType == "" {
+		cacheType = storage.CacheTypeAll
+	}
+
+	if err := h.cache.Flush(cacheType); err != nil {
+		logger.Errorf("[Admin] Failed to flush %s cache: %v", cacheType, err)
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, FlushResponse{Type: cacheType})
+}
+
+// authorize checks the request method and bearer token, writing an error response on failure
+func (h *Handler) authorize(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method not allowed"})
+		return false
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+		logger.Warnf("[Admin] Unauthorized request to %s from %s", r.URL.Path, r.RemoteAddr)
+		writeJSON(w, http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+		return false
+	}
+
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Warnf("[Admin] Error writing response: %v", err)
+	}
+}
@@ -11,9 +11,11 @@ import (
 	"time"
 
 	"github.com/cshum/vipsgen/vips"
+	"github.com/sashko-guz/mage/internal/admin"
 	"github.com/sashko-guz/mage/internal/auth/signature"
 	"github.com/sashko-guz/mage/internal/config"
 	magehttp "github.com/sashko-guz/mage/internal/http"
+	"github.com/sashko-guz/mage/internal/imaging/operations"
 	"github.com/sashko-guz/mage/internal/observability/health"
 	"github.com/sashko-guz/mage/internal/observability/metrics"
 	"github.com/sashko-guz/mage/internal/pkg/logger"
@@ -46,9 +48,34 @@ func (a *App) Run() error {
 	}
 
 	// Initialize parser
-	parser.Init(a.cfg.Resize.MaxWidth, a.cfg.Resize.MaxHeight, a.cfg.Resize.MaxResolution)
+	parser.Init(a.cfg.Resize.MaxWidth, a.cfg.Resize.MaxHeight, a.cfg.Resize.MaxResolution,
+		a.cfg.Resize.MaxAnimationFrames, a.cfg.Resize.MaxAnimationResolution)
 	parser.SetSignatureLength(a.cfg.Signature.Length)
 	parser.SetSignatureValidationEnabled(a.cfg.Signature.Secret != "")
+	if err := parser.SetDefaultFormat(a.cfg.Output.DefaultFormat); err != nil {
+		return fmt.Errorf("invalid DEFAULT_OUTPUT_FORMAT: %w", err)
+	}
+	if err := parser.SetDefaultMetadata(a.cfg.Output.Metadata); err != nil {
+		return fmt.Errorf("invalid DEFAULT_METADATA: %w", err)
+	}
+	if err := parser.SetDefaultEncoding(operations.EncodeOptions{
+		Progressive: a.cfg.Output.Progressive,
+		Optimize:    a.cfg.Output.Optimize,
+		Subsampling: a.cfg.Output.Subsampling,
+		Lossless:    a.cfg.Output.Lossless,
+		Palette:     a.cfg.Output.Palette,
+		WebPEffort:  a.cfg.Output.WebPEffort,
+		AVIFEffort:  a.cfg.Output.AVIFEffort,
+	}); err != nil {
+		return fmt.Errorf("invalid output encoding defaults: %w", err)
+	}
+	presetCount, err := parser.LoadPresets(a.cfg.Presets.File)
+	if err != nil {
+		return fmt.Errorf("failed to load presets: %w", err)
+	}
+	if presetCount > 0 {
+		log.Printf("[App] Loaded %d presets from %s", presetCount, a.cfg.Presets.File)
+	}
 
 	a.logStartup()
 
@@ -76,6 +103,17 @@ func (a *App) logStartup() {
 	log.Printf("[App] Resize limits: max width=%d px, max height=%d px, max resolution=%d px",
 		a.cfg.Resize.MaxWidth, a.cfg.Resize.MaxHeight, a.cfg.Resize.MaxResolution)
 	log.Printf("[App] Max input image size: %d MB", a.cfg.Resize.MaxInputSize/(1024*1024))
+	log.Printf("[App] Animation limits: max frames=%d, max resolution=%d px",
+		a.cfg.Resize.MaxAnimationFrames, a.cfg.Resize.MaxAnimationResolution)
+	if a.cfg.Output.DefaultFormat != "" {
+		log.Printf("[App] Default output format: %s", a.cfg.Output.DefaultFormat)
+	}
+	if a.cfg.Output.Metadata != "" {
+		log.Printf("[App] Default metadata: keep(%s)", a.cfg.Output.Metadata)
+	}
+	log.Printf("[App] Default encoding: progressive=%t, optimize=%t, subsampling=%q, lossless=%t, palette=%t, webp effort=%d, avif effort=%d",
+		a.cfg.Output.Progressive, a.cfg.Output.Optimize, a.cfg.Output.Subsampling, a.cfg.Output.Lossless,
+		a.cfg.Output.Palette, a.cfg.Output.WebPEffort, a.cfg.Output.AVIFEffort)
 }
 
 func (a *App) initStorage() error {
@@ -97,7 +135,8 @@ func (a *App) initStorage() error {
 }
 
 func (a *App) initServer() error {
-	imageProcessor := processor.NewImageProcessor()
+	// Overlays (watermarks) are fetched through the same storage and source cache as images
+	imageProcessor := processor.NewImageProcessor(a.storage)
 
 	config := handler.ThumbnailHandlerConfig{
 		SignatureCfg: signature.Config{
@@ -127,9 +166,20 @@ func (a *App) initServer() error {
 	}
 	healthHandler := health.NewHandler(a.cfg.Health.ReadinessTimeout, healthCheckers...)
 
+	// Create admin handler only when a token is configured and caching is enabled
+	var adminHandler *admin.Handler
+	if a.cfg.Admin.Token != "" {
+		if cached, ok := a.storage.(*storage.CachedStorage); ok {
+			adminHandler = admin.NewHandler(cached, a.cfg.Admin.Token)
+			log.Printf("[App] Cache admin API: ENABLED")
+		} else {
+			log.Printf("[App] Cache admin API: DISABLED (no caches configured)")
+		}
+	}
+
 	// Build router and server
 	router := magehttp.NewRouter(a.cfg.CORS, a.metrics)
-	router.RegisterRoutes(thumbnailHandler, healthHandler, a.cfg.Metrics.Enabled, a.cfg.Metrics.Path)
+	router.RegisterRoutes(thumbnailHandler, healthHandler, adminHandler, a.cfg.Metrics.Enabled, a.cfg.Metrics.Path)
 
 	a.server = magehttp.NewServer(a.cfg.HTTP, router)
 
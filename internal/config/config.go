@@ -11,8 +11,11 @@ type Config struct {
 	CORS      CORSConfig
 	Signature SignatureConfig
 	Resize    ResizeConfig
+	Output    OutputConfig
+	Presets   PresetsConfig
 	Metrics   MetricsConfig
 	Health    HealthConfig
+	Admin     AdminConfig
 
 	CacheControlResponseHeader string
 }
@@ -26,6 +29,12 @@ type HealthConfig struct {
 	ReadinessTimeout time.Duration
 }
 
+// AdminConfig configures the cache administration endpoints.
+// The endpoints are disabled when Token is empty.
+type AdminConfig struct {
+	Token string
+}
+
 type HTTPConfig struct {
 	Port              string
 	ReadTimeout       time.Duration
@@ -55,6 +64,29 @@ type ResizeConfig struct {
 	MaxHeight     int
 	MaxResolution int
 	MaxInputSize  int
+
+	MaxAnimationFrames     int // Frames loaded by animated(), 0 = unlimited
+	MaxAnimationResolution int // Pixels across all frames (frames × width × height), 0 = unlimited
+}
+
+// OutputConfig holds server-wide defaults for encoded thumbnails.
+type OutputConfig struct {
+	DefaultFormat string // "auto", a format name, or empty to detect from the source extension
+	Metadata      string // Metadata kept when the URL has no strip()/keep(), e.g. "none" or "icc"; empty keeps libvips defaults
+
+	// Encoder defaults, overridden per URL by the matching filters
+	Progressive bool   // Progressive JPEG, interlaced PNG and GIF
+	Optimize    bool   // JPEG trellis quantisation and optimised Huffman coding
+	Subsampling string // JPEG/AVIF/HEIC chroma subsampling: auto, 444 or 420; empty keeps libvips default
+	Lossless    bool   // Lossless WebP, AVIF, HEIC and JPEG XL
+	Palette     bool   // 8-bit palette PNG
+	WebPEffort  int    // 1..6, 0 keeps libvips default
+	AVIFEffort  int    // 1..9, also used for HEIC, 0 keeps libvips default
+}
+
+// PresetsConfig points to the optional named presets file.
+type PresetsConfig struct {
+	File string
 }
 
 func Load() *Config {
@@ -70,6 +102,9 @@ func Load() *Config {
 		Health: HealthConfig{
 			ReadinessTimeout: getEnvDurationSeconds("HEALTH_READINESS_TIMEOUT_SECONDS", 5),
 		},
+		Admin: AdminConfig{
+			Token: getEnv("ADMIN_TOKEN", ""),
+		},
 		Signature: SignatureConfig{
 			Secret:    getEnv("SIGNATURE_SECRET", ""),
 			Algorithm: getEnv("SIGNATURE_ALGO", "sha256"),
@@ -80,7 +115,7 @@ func Load() *Config {
 			AllowOrigin:   getEnv("CORS_ALLOW_ORIGIN", "*"),
 			AllowMethods:  getEnv("CORS_ALLOW_METHODS", "GET, HEAD, OPTIONS"),
 			AllowHeaders:  getEnv("CORS_ALLOW_HEADERS", "Origin, Content-Type, Accept, Authorization"),
-			ExposeHeaders: getEnv("CORS_EXPOSE_HEADERS", "Content-Type, Content-Length, Cache-Control, X-Mage-Cache"),
+			ExposeHeaders: getEnv("CORS_EXPOSE_HEADERS", "Content-Type, Content-Length, Cache-Control, X-Mage-Cache, X-Mage-Quality"),
 			MaxAge:        getEnvInt("CORS_MAX_AGE", 86400),
 		},
 		HTTP: HTTPConfig{
@@ -91,11 +126,28 @@ func Load() *Config {
 			IdleTimeout:       getEnvDurationSeconds("HTTP_IDLE_TIMEOUT_SECONDS", 120),
 			MaxHeaderBytes:    getEnvInt("HTTP_MAX_HEADER_BYTES", 1<<20),
 		},
+		Output: OutputConfig{
+			DefaultFormat: getEnv("DEFAULT_OUTPUT_FORMAT", ""),
+			Metadata:      getEnv("DEFAULT_METADATA", ""),
+			Progressive:   getEnvBool("DEFAULT_PROGRESSIVE", false),
+			Optimize:      getEnvBool("DEFAULT_OPTIMIZE", false),
+			Subsampling:   getEnv("DEFAULT_SUBSAMPLING", ""),
+			Lossless:      getEnvBool("DEFAULT_LOSSLESS", false),
+			Palette:       getEnvBool("DEFAULT_PALETTE", false),
+			WebPEffort:    getEnvIntMin("DEFAULT_WEBP_EFFORT", 0, 0),
+			AVIFEffort:    getEnvIntMin("DEFAULT_AVIF_EFFORT", 0, 0),
+		},
+		Presets: PresetsConfig{
+			File: getEnv("PRESETS_FILE", ""),
+		},
 		Resize: ResizeConfig{
 			MaxWidth:      maxWidth,
 			MaxHeight:     maxHeight,
 			MaxResolution: getEnvInt("MAX_RESIZE_RESOLUTION", maxWidth*maxHeight),
 			MaxInputSize:  getEnvInt("MAX_INPUT_IMAGE_SIZE_MB", 64) * 1024 * 1024,
+
+			MaxAnimationFrames:     getEnvIntMin("MAX_ANIMATION_FRAMES", 100, 0),
+			MaxAnimationResolution: getEnvIntMin("MAX_ANIMATION_RESOLUTION", 100_000_000, 0),
 		},
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/sashko-guz/mage/internal/admin"
 	"github.com/sashko-guz/mage/internal/config"
 	"github.com/sashko-guz/mage/internal/http/middleware"
 	"github.com/sashko-guz/mage/internal/observability/health"
@@ -37,15 +38,25 @@ func NewRouter(cors config.CORSConfig, m *metrics.Metrics) *Router {
 }
 
 // RegisterRoutes registers all application routes
-func (rt *Router) RegisterRoutes(thumbnailHandler *handler.ThumbnailHandler, healthHandler *health.Handler, metricsEnabled bool, metricsPath string) {
+// adminHandler may be nil, in which case the cache administration endpoints are not registered
+func (rt *Router) RegisterRoutes(thumbnailHandler *handler.ThumbnailHandler, healthHandler *health.Handler, adminHandler *admin.Handler, metricsEnabled bool, metricsPath string) {
 	// Thumbnail endpoints
 	routes.Add("/thumbs/", thumbnailHandler)
 	routes.Add("/t/", thumbnailHandler)
 
+	// Image metadata endpoint, same path syntax as thumbnails
+	routes.Add("/meta/", http.HandlerFunc(thumbnailHandler.ServeMeta))
+
 	// Health endpoints
 	routes.Add("/health", http.HandlerFunc(healthHandler.Liveness))
 	routes.Add("/ready", http.HandlerFunc(healthHandler.Readiness))
 
+	// Cache administration endpoints
+	if adminHandler != nil {
+		routes.Add("/admin/cache/purge", http.HandlerFunc(adminHandler.Purge))
+		routes.Add("/admin/cache/flush", http.HandlerFunc(adminHandler.Flush))
+	}
+
 	// Metrics endpoint
 	if metricsEnabled && rt.metrics != nil {
 		routes.Add(metricsPath, rt.metrics.Handler())
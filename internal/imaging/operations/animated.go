@@ -0,0 +1,167 @@
+package operations
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// ErrAnimationTooLarge is returned when an animated source exceeds the frame or pixel limits.
+var ErrAnimationTooLarge = errors.New("animation is too large")
+
+// AnimatedOperation handles animated() filter.
+// All frames of animated GIF/WebP sources are loaded and processed one by one, instead of
+// only the first frame. Sources without animation are processed as usual.
+type AnimatedOperation struct {
+	Format string // Set during validation to check that the format can be animated
+
+	maxFrames     int
+	maxResolution int
+}
+
+func NewAnimatedOperation(maxFrames, maxResolution int) *AnimatedOperation {
+	return &AnimatedOperation{
+		maxFrames:     maxFrames,
+		maxResolution: maxResolution,
+	}
+}
+
+func (o *AnimatedOperation) Name() string {
+	return "animated"
+}
+
+func (o *AnimatedOperation) Aliases() []string {
+	return []string{"anim"}
+}
+
+func (o *AnimatedOperation) Clone() Operation {
+	return NewAnimatedOperation(o.maxFrames, o.maxResolution)
+}
+
+func (o *AnimatedOperation) Parse(filter string) (bool, error) {
+	if !matchesFilter(filter, o.Name(), o.Aliases()) {
+		return false, nil
+	}
+
+	args, err := filterArgs(filter, o.Name())
+	if err != nil {
+		return false, err
+	}
+	if len(args) != 0 {
+		return false, fmt.Errorf("animated filter expects no parameters, got %d", len(args))
+	}
+
+	return true, nil
+}
+
+// SetOutputFormat records the output format for animation validation
+func (o *AnimatedOperation) SetOutputFormat(format string) {
+	o.Format = format
+}
+
+// Validate checks that the output format can carry multiple frames.
+// format(auto) is accepted because it falls back to GIF for animated images.
+func (o *AnimatedOperation) Validate() error {
+	switch o.Format {
+	case "webp", "gif", "avif", FormatAuto:
+		return nil
+	default:
+		return fmt.Errorf("animated requires WebP, GIF, or AVIF format (use filters:format(webp), filters:format(gif), or filters:format(avif))")
+	}
+}
+
+// CheckLimits rejects sources with too many frames or too many pixels across all frames
+func (o *AnimatedOperation) CheckLimits(img *vips.Image) error {
+	frames := frameCount(img)
+	if o.maxFrames > 0 && frames > o.maxFrames {
+		return fmt.Errorf("%w: %d frames exceed limit %d", ErrAnimationTooLarge, frames, o.maxFrames)
+	}
+
+	pixels := frames * img.Width() * img.PageHeight()
+	if o.maxResolution > 0 && pixels > o.maxResolution {
+		return fmt.Errorf("%w: %d frames of %dx%d (%d px) exceed limit %d px",
+			ErrAnimationTooLarge, frames, img.Width(), img.PageHeight(), pixels, o.maxResolution)
+	}
+	return nil
+}
+
+func (o *AnimatedOperation) Apply(img *vips.Image) (*vips.Image, error) {
+	// Animation is applied by ApplyAll, which processes each frame separately
+	return img, nil
+}
+
+// isAnimated reports whether more than one frame was loaded. Loaders report the number of
+// pages in the file even when only the first one is loaded, so the loaded height is compared.
+func isAnimated(img *vips.Image) bool {
+	return img.Height() > img.PageHeight()
+}
+
+// frameCount returns the number of loaded frames
+func frameCount(img *vips.Image) int {
+	return img.Height() / img.PageHeight()
+}
+
+// applyPerFrame splits an animated image into frames, runs process on each of them and joins
+// the results into a new animated image with the original frame delays and loop count.
+// The caller owns the returned image; img is left unchanged.
+func applyPerFrame(img *vips.Image, process func(*vips.Image) error) (*vips.Image, error) {
+	pages := frameCount(img)
+	pageHeight := img.PageHeight()
+	delay, _ := img.PageDelay()
+	loop := img.Loop()
+
+	frames := make([]*vips.Image, 0, pages)
+	defer func() {
+		for _, frame := range frames {
+			frame.Close()
+		}
+	}()
+
+	for i := range pages {
+		frame, err := img.Copy(nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy frame %d: %w", i, err)
+		}
+		frames = append(frames, frame)
+
+		if err := frame.ExtractArea(0, i*pageHeight, img.Width(), pageHeight); err != nil {
+			return nil, fmt.Errorf("failed to extract frame %d: %w", i, err)
+		}
+		if err := frame.SetPages(1); err != nil {
+			return nil, fmt.Errorf("failed to extract frame %d: %w", i, err)
+		}
+		if err := process(frame); err != nil {
+			return nil, fmt.Errorf("frame %d: %w", i, err)
+		}
+
+		// Frames are stacked vertically, so they must all end up the same size
+		if frame.Width() != frames[0].Width() || frame.Height() != frames[0].Height() {
+			return nil, fmt.Errorf("frame %d is %dx%d after processing, expected %dx%d",
+				i, frame.Width(), frame.Height(), frames[0].Width(), frames[0].Height())
+		}
+	}
+
+	joined, err := vips.NewArrayjoin(frames, &vips.ArrayjoinOptions{Across: 1})
+	if err != nil {
+		return nil, fmt.Errorf("failed to join frames: %w", err)
+	}
+
+	if err := joined.SetPages(pages); err != nil {
+		joined.Close()
+		return nil, fmt.Errorf("failed to join frames: %w", err)
+	}
+	if err := joined.SetPageHeight(frames[0].Height()); err != nil {
+		joined.Close()
+		return nil, fmt.Errorf("failed to join frames: %w", err)
+	}
+	if len(delay) == pages {
+		if err := joined.SetArrayInt("delay", delay); err != nil {
+			joined.Close()
+			return nil, fmt.Errorf("failed to set frame delays: %w", err)
+		}
+	}
+	joined.SetLoop(loop)
+
+	return joined, nil
+}
@@ -0,0 +1,80 @@
+package operations
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// filterArgs validates the closing parenthesis and returns the trimmed, comma-separated
+// arguments of a filter such as "blur(1.5)". Commas inside nested parentheses, as in
+// "fit(fill,rgb(1,2,3))", do not split arguments. Returns an empty slice for "name()".
+func filterArgs(filter, name string) ([]string, error) {
+	if !strings.HasSuffix(filter, ")") {
+		return nil, fmt.Errorf("%s filter missing closing parenthesis", name)
+	}
+
+	content := strings.TrimSpace(filter[strings.Index(filter, "(")+1 : len(filter)-1])
+	if content == "" {
+		return []string{}, nil
+	}
+
+	var parts []string
+	depth, start := 0, 0
+	for i, ch := range content {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("%s filter has unbalanced parentheses", name)
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(content[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("%s filter has unbalanced parentheses", name)
+	}
+	parts = append(parts, strings.TrimSpace(content[start:]))
+
+	return parts, nil
+}
+
+// parseFloatArg parses a finite decimal filter argument. Range checks belong in Validate.
+func parseFloatArg(name, s string) (float64, error) {
+	if s == "" {
+		return 0, fmt.Errorf("%s is empty", name)
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("%s must be a number, got: %s", name, s)
+	}
+
+	return value, nil
+}
+
+// base64ArgPrefix marks a base64url-encoded filter argument. Free-form arguments such as
+// paths and captions use it for characters the URL syntax reserves ("/", ";", ",", "(", ")").
+const base64ArgPrefix = "b64:"
+
+// decodeTextArg returns a free-form argument, decoding the b64: form if present
+func decodeTextArg(name, arg string) (string, error) {
+	encoded, ok := strings.CutPrefix(arg, base64ArgPrefix)
+	if !ok {
+		return arg, nil
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	if err != nil {
+		return "", fmt.Errorf("invalid base64 %s: %s", name, encoded)
+	}
+	return string(decoded), nil
+}
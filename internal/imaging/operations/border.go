@@ -0,0 +1,95 @@
+package operations
+
+import (
+	"fmt"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// Border width bounds
+const (
+	minBorderWidth = 1
+	maxBorderWidth = 100
+)
+
+// BorderOperation handles border(width[,color]) filter.
+// The outline is drawn inside the image edges, following radius() or circle() if present.
+type BorderOperation struct {
+	Width int
+	Color Color     // Default black
+	Shape MaskShape // Set by the parser from radius()/circle()
+}
+
+func NewBorderOperation() *BorderOperation {
+	return &BorderOperation{
+		Color: ColorBlack,
+	}
+}
+
+func (o *BorderOperation) Name() string {
+	return "border"
+}
+
+func (o *BorderOperation) Aliases() []string {
+	return []string{}
+}
+
+func (o *BorderOperation) Clone() Operation {
+	return NewBorderOperation()
+}
+
+// Stage draws the border after resize, so the width is in output pixels
+func (o *BorderOperation) Stage() Stage {
+	return StagePostResize
+}
+
+func (o *BorderOperation) Parse(filter string) (bool, error) {
+	if !matchesFilter(filter, o.Name(), o.Aliases()) {
+		return false, nil
+	}
+
+	args, err := filterArgs(filter, o.Name())
+	if err != nil {
+		return false, err
+	}
+	if len(args) == 0 || len(args) > 2 {
+		return false, fmt.Errorf("border filter expects 1 or 2 parameters (width,color), got %d", len(args))
+	}
+
+	if o.Width, err = parsePositiveInt(args[0]); err != nil {
+		return false, fmt.Errorf("invalid border width: %w", err)
+	}
+
+	if len(args) == 2 {
+		if o.Color, err = ParseColor(args[1]); err != nil {
+			return false, fmt.Errorf("invalid border color: %w", err)
+		}
+	}
+
+	return true, nil
+}
+
+// Validate checks the border width range
+func (o *BorderOperation) Validate() error {
+	if o.Width < minBorderWidth || o.Width > maxBorderWidth {
+		return fmt.Errorf("border width must be between %d and %d, got: %d", minBorderWidth, maxBorderWidth, o.Width)
+	}
+	return nil
+}
+
+func (o *BorderOperation) Apply(img *vips.Image) (*vips.Image, error) {
+	attrs := fmt.Sprintf(`fill="none" stroke="#%02x%02x%02x" stroke-opacity="%.3f" stroke-width="%d"`,
+		o.Color.R, o.Color.G, o.Color.B, float64(o.Color.A)/255, o.Width)
+
+	// Stroke is centred on the outline, so inset it by half the width to keep it inside
+	outline, err := renderShape(img.Width(), img.Height(), o.Shape, float64(o.Width)/2, attrs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render border: %w", err)
+	}
+	defer outline.Close()
+
+	if err := compositeOverlay(img, outline, GravityCentre); err != nil {
+		return nil, fmt.Errorf("failed to draw border: %w", err)
+	}
+	return img, nil
+}
@@ -0,0 +1,59 @@
+package operations
+
+import (
+	"fmt"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// BrightnessOperation handles brightness(-100..100) filter.
+// The value is a percentage change: -100 is black, 100 doubles every channel.
+type BrightnessOperation struct {
+	Brightness float64
+}
+
+func NewBrightnessOperation() *BrightnessOperation {
+	return &BrightnessOperation{}
+}
+
+func (o *BrightnessOperation) Name() string {
+	return "brightness"
+}
+
+func (o *BrightnessOperation) Aliases() []string {
+	return []string{"br"}
+}
+
+func (o *BrightnessOperation) Clone() Operation {
+	return NewBrightnessOperation()
+}
+
+func (o *BrightnessOperation) Stage() Stage {
+	return StagePostResize
+}
+
+func (o *BrightnessOperation) Parse(filter string) (bool, error) {
+	if !matchesFilter(filter, o.Name(), o.Aliases()) {
+		return false, nil
+	}
+
+	value, err := parseToneArg(filter, o.Name())
+	if err != nil {
+		return false, err
+	}
+
+	o.Brightness = value
+	return true, nil
+}
+
+// Validate checks that brightness is within -100..100
+func (o *BrightnessOperation) Validate() error {
+	return validateToneArg("brightness", o.Brightness)
+}
+
+func (o *BrightnessOperation) Apply(img *vips.Image) (*vips.Image, error) {
+	if err := linearColour(img, 1+o.Brightness/100, 0); err != nil {
+		return nil, fmt.Errorf("failed to adjust brightness: %w", err)
+	}
+	return img, nil
+}
@@ -0,0 +1,72 @@
+package operations
+
+import (
+	"fmt"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// CircleOperation handles circle() filter.
+// Everything outside a centred circle with the diameter of the shorter side becomes transparent.
+type CircleOperation struct {
+	Format string // Set during validation to check transparent compatibility
+}
+
+func NewCircleOperation() *CircleOperation {
+	return &CircleOperation{}
+}
+
+func (o *CircleOperation) Name() string {
+	return "circle"
+}
+
+func (o *CircleOperation) Aliases() []string {
+	return []string{}
+}
+
+func (o *CircleOperation) Clone() Operation {
+	return NewCircleOperation()
+}
+
+// Stage masks after resize, so the circle fits the output image
+func (o *CircleOperation) Stage() Stage {
+	return StagePostResize
+}
+
+func (o *CircleOperation) Parse(filter string) (bool, error) {
+	if !matchesFilter(filter, o.Name(), o.Aliases()) {
+		return false, nil
+	}
+
+	args, err := filterArgs(filter, o.Name())
+	if err != nil {
+		return false, err
+	}
+	if len(args) != 0 {
+		return false, fmt.Errorf("circle filter expects no parameters, got %d", len(args))
+	}
+
+	return true, nil
+}
+
+// Shape returns the mask outline, followed by border()
+func (o *CircleOperation) Shape() MaskShape {
+	return MaskShape{Circle: true}
+}
+
+// SetOutputFormat records the output format for transparency validation
+func (o *CircleOperation) SetOutputFormat(format string) {
+	o.Format = format
+}
+
+// Validate checks that the output format can carry the transparent surroundings
+func (o *CircleOperation) Validate() error {
+	return checkAlphaFormat("circle", o.Format, 0)
+}
+
+func (o *CircleOperation) Apply(img *vips.Image) (*vips.Image, error) {
+	if err := applyMask(img, o.Shape()); err != nil {
+		return nil, err
+	}
+	return img, nil
+}
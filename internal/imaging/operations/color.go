@@ -0,0 +1,178 @@
+package operations
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// Color is an 8-bit RGBA colour used for fill and background areas
+type Color struct {
+	R, G, B, A uint8
+}
+
+// Common colours
+var (
+	ColorBlack       = Color{0, 0, 0, 255}
+	ColorWhite       = Color{255, 255, 255, 255}
+	ColorTransparent = Color{0, 0, 0, 0}
+)
+
+// namedColors is the small palette accepted by name
+var namedColors = map[string]Color{
+	"black":       ColorBlack,
+	"white":       ColorWhite,
+	"transparent": ColorTransparent,
+	"gray":        {128, 128, 128, 255},
+	"grey":        {128, 128, 128, 255},
+	"silver":      {192, 192, 192, 255},
+	"red":         {255, 0, 0, 255},
+	"green":       {0, 128, 0, 255},
+	"blue":        {0, 0, 255, 255},
+	"yellow":      {255, 255, 0, 255},
+	"orange":      {255, 165, 0, 255},
+	"purple":      {128, 0, 128, 255},
+	"navy":        {0, 0, 128, 255},
+	"teal":        {0, 128, 128, 255},
+}
+
+// ParseColor parses a colour given as a name, hex (#RRGGBB, #RRGGBBAA, or the same
+// without "#"), rgb(r,g,b) or rgba(r,g,b,a) with alpha in 0..1.
+func ParseColor(s string) (Color, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return Color{}, fmt.Errorf("color is empty")
+	}
+
+	if c, ok := namedColors[s]; ok {
+		return c, nil
+	}
+
+	if strings.HasPrefix(s, "rgb(") || strings.HasPrefix(s, "rgba(") {
+		return parseRGBFunction(s)
+	}
+
+	if c, ok := parseHexColor(strings.TrimPrefix(s, "#")); ok {
+		return c, nil
+	}
+
+	return Color{}, fmt.Errorf("unsupported color: %s (use a name, #RRGGBB, #RRGGBBAA, rgb(r,g,b) or rgba(r,g,b,a))", s)
+}
+
+func parseHexColor(hex string) (Color, bool) {
+	if len(hex) != 6 && len(hex) != 8 {
+		return Color{}, false
+	}
+
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return Color{}, false
+	}
+
+	if len(hex) == 6 {
+		return Color{uint8(value >> 16), uint8(value >> 8), uint8(value), 255}, true
+	}
+	return Color{uint8(value >> 24), uint8(value >> 16), uint8(value >> 8), uint8(value)}, true
+}
+
+func parseRGBFunction(s string) (Color, error) {
+	hasAlpha := strings.HasPrefix(s, "rgba(")
+	if !strings.HasSuffix(s, ")") {
+		return Color{}, fmt.Errorf("color %s missing closing parenthesis", s)
+	}
+
+	parts := strings.Split(s[strings.Index(s, "(")+1:len(s)-1], ",")
+	expected := 3
+	if hasAlpha {
+		expected = 4
+	}
+	if len(parts) != expected {
+		return Color{}, fmt.Errorf("color %s expects %d components", s, expected)
+	}
+
+	var channels [3]uint8
+	for i := range channels {
+		value, err := strconv.Atoi(strings.TrimSpace(parts[i]))
+		if err != nil || value < 0 || value > 255 {
+			return Color{}, fmt.Errorf("color %s: components must be integers between 0 and 255", s)
+		}
+		channels[i] = uint8(value)
+	}
+
+	c := Color{channels[0], channels[1], channels[2], 255}
+	if hasAlpha {
+		alpha, err := strconv.ParseFloat(strings.TrimSpace(parts[3]), 64)
+		if err != nil || math.IsNaN(alpha) || alpha < 0 || alpha > 1 {
+			return Color{}, fmt.Errorf("color %s: alpha must be between 0 and 1", s)
+		}
+		c.A = uint8(math.Round(alpha * 255))
+	}
+
+	return c, nil
+}
+
+// IsOpaque reports whether the colour has no transparency
+func (c Color) IsOpaque() bool {
+	return c.A == 255
+}
+
+// String returns the palette name when there is one, otherwise the hex form
+func (c Color) String() string {
+	switch c {
+	case ColorBlack:
+		return "black"
+	case ColorWhite:
+		return "white"
+	case ColorTransparent:
+		return "transparent"
+	}
+	if c.IsOpaque() {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
+
+// Background returns the colour as a background vector matching the image's band layout:
+// grey (1), grey+alpha (2), RGB (3) or RGBA (4), scaled for 16-bit images.
+// Non-opaque colours need an alpha channel, which is added to the image when missing.
+func (c Color) Background(img *vips.Image) ([]float64, error) {
+	if !c.IsOpaque() && !img.HasAlpha() {
+		if err := img.Addalpha(); err != nil {
+			return nil, fmt.Errorf("failed to add alpha channel: %w", err)
+		}
+	}
+
+	scale := 1.0
+	if interpretation := img.Interpretation(); interpretation == vips.InterpretationRgb16 || interpretation == vips.InterpretationGrey16 {
+		scale = 65535.0 / 255.0
+	}
+
+	bands := img.Bands()
+	hasAlpha := img.HasAlpha()
+	colorBands := bands
+	if hasAlpha {
+		colorBands--
+	}
+
+	bg := make([]float64, 0, bands)
+	if colorBands < 3 {
+		// Rec. 709 luma, matching libvips sRGB to greyscale conversion
+		luma := 0.2126*float64(c.R) + 0.7152*float64(c.G) + 0.0722*float64(c.B)
+		for range colorBands {
+			bg = append(bg, math.Round(luma)*scale)
+		}
+	} else {
+		bg = append(bg, float64(c.R)*scale, float64(c.G)*scale, float64(c.B)*scale)
+		for range colorBands - 3 {
+			bg = append(bg, 0)
+		}
+	}
+	if hasAlpha {
+		bg = append(bg, float64(c.A)*scale)
+	}
+
+	return bg, nil
+}
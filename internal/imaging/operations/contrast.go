@@ -0,0 +1,62 @@
+package operations
+
+import (
+	"fmt"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// ContrastOperation handles contrast(-100..100) filter.
+// The value is a percentage change around mid-grey: -100 is flat grey, 100 doubles contrast.
+type ContrastOperation struct {
+	Contrast float64
+}
+
+func NewContrastOperation() *ContrastOperation {
+	return &ContrastOperation{}
+}
+
+func (o *ContrastOperation) Name() string {
+	return "contrast"
+}
+
+func (o *ContrastOperation) Aliases() []string {
+	return []string{"con"}
+}
+
+func (o *ContrastOperation) Clone() Operation {
+	return NewContrastOperation()
+}
+
+func (o *ContrastOperation) Stage() Stage {
+	return StagePostResize
+}
+
+func (o *ContrastOperation) Parse(filter string) (bool, error) {
+	if !matchesFilter(filter, o.Name(), o.Aliases()) {
+		return false, nil
+	}
+
+	value, err := parseToneArg(filter, o.Name())
+	if err != nil {
+		return false, err
+	}
+
+	o.Contrast = value
+	return true, nil
+}
+
+// Validate checks that contrast is within -100..100
+func (o *ContrastOperation) Validate() error {
+	return validateToneArg("contrast", o.Contrast)
+}
+
+func (o *ContrastOperation) Apply(img *vips.Image) (*vips.Image, error) {
+	factor := 1 + o.Contrast/100
+	mid := maxBandValue(img) / 2
+
+	if err := linearColour(img, factor, mid*(1-factor)); err != nil {
+		return nil, fmt.Errorf("failed to adjust contrast: %w", err)
+	}
+	return img, nil
+}
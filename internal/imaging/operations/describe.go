@@ -0,0 +1,184 @@
+package operations
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// exifSummaryTags lists the EXIF tags reported by Describe when the request keeps EXIF.
+// GPS tags are left out on purpose, so location is not disclosed; HasGPS only reports
+// that it is present.
+var exifSummaryTags = []string{
+	"Make",
+	"Model",
+	"LensModel",
+	"Software",
+	"DateTimeOriginal",
+	"ExposureTime",
+	"FNumber",
+	"ISOSpeedRatings",
+	"FocalLength",
+	"Artist",
+	"Copyright",
+}
+
+// ImageInfo describes a source image and the output the request operations would produce
+type ImageInfo struct {
+	Source SourceInfo `json:"source"`
+	Output OutputInfo `json:"output"`
+}
+
+// SourceInfo describes the stored source image. Width and height are as stored, before
+// EXIF orientation is applied.
+type SourceInfo struct {
+	Width       int               `json:"width"`
+	Height      int               `json:"height"`
+	Format      string            `json:"format"`
+	Bands       int               `json:"bands"`
+	HasAlpha    bool              `json:"has_alpha"`
+	Orientation int               `json:"orientation"`
+	Pages       int               `json:"pages"`
+	Size        int               `json:"size"` // Bytes
+	EXIF        map[string]string `json:"exif,omitempty"`
+	HasGPS      bool              `json:"has_gps"`
+}
+
+// OutputInfo describes the image the request operations would produce.
+// For animations, height is the height of a single frame.
+type OutputInfo struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Format string `json:"format"`
+	Frames int    `json:"frames"`
+}
+
+// Describe reads the source image properties and runs the request operations without
+// encoding, to report the output dimensions and format. Overlays do not change the
+// dimensions, so they are skipped and their resources are not fetched.
+// The EXIF summary follows the metadata policy of the request, so strip() and
+// DEFAULT_METADATA hide it as in thumbnails.
+func Describe(ctx context.Context, imageData []byte, req *Request) (*ImageInfo, error) {
+	keepEXIF := true // Without a metadata operation, libvips keeps all metadata on export
+	var ops []Operation
+	for _, op := range req.Operations {
+		if v, ok := op.(*MetadataOperation); ok {
+			keepEXIF = v.KeepsEXIF()
+		}
+		if _, ok := op.(ResourceUser); ok || stageOf(op) == StageOverlay {
+			continue
+		}
+		ops = append(ops, op)
+	}
+
+	sourceImg, err := loadImage(imageData)
+	if err != nil {
+		return nil, err
+	}
+	// Read the header before rendering, which rotates the image in place
+	source := describeSource(sourceImg, len(imageData), keepEXIF)
+
+	img, animated, err := renderImage(ctx, sourceImg, imageData, ops, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer img.Close()
+
+	formatOp := NewFormatOperation()
+	for _, op := range req.Operations {
+		if v, ok := op.(*FormatOperation); ok {
+			formatOp = v
+		}
+	}
+	formatOp.Animated = animated
+
+	output := OutputInfo{
+		Width:  img.Width(),
+		Height: img.Height(),
+		Format: normalizeOutputFormat(formatOp.outputFormat(img)),
+		Frames: 1,
+	}
+	if animated {
+		output.Height = img.PageHeight()
+		output.Frames = frameCount(img)
+	}
+
+	return &ImageInfo{Source: *source, Output: output}, nil
+}
+
+// describeSource reads the header of an image opened by loadImage, without decoding pixels.
+// The EXIF summary is only included when keepEXIF is set.
+func describeSource(img *vips.Image, size int, keepEXIF bool) *SourceInfo {
+	info := &SourceInfo{
+		Width:       img.Width(),
+		Height:      img.Height(),
+		Format:      string(img.Format()),
+		Bands:       img.Bands(),
+		HasAlpha:    img.HasAlpha(),
+		Orientation: max(img.Orientation(), 1),
+		Pages:       max(img.Pages(), 1),
+		Size:        size,
+	}
+	exif, hasGPS := exifSummary(img.Exif())
+	info.HasGPS = hasGPS
+	if keepEXIF {
+		info.EXIF = exif
+	}
+
+	return info
+}
+
+// exifSummary picks the tags in exifSummaryTags from libvips EXIF fields, which are named
+// "exif-ifd<N>-<Tag>" and hold values like "Canon (Canon, ASCII, 6 components, 6 bytes)".
+// Returns nil when none of the tags are present.
+func exifSummary(fields map[string]string) (map[string]string, bool) {
+	var summary map[string]string
+	hasGPS := false
+
+	for field, value := range fields {
+		rest, ok := strings.CutPrefix(field, "exif-ifd")
+		if !ok {
+			continue
+		}
+		_, tag, ok := strings.Cut(rest, "-")
+		if !ok {
+			continue
+		}
+
+		if strings.HasPrefix(tag, "GPS") {
+			hasGPS = true
+			continue
+		}
+		if !slices.Contains(exifSummaryTags, tag) {
+			continue
+		}
+
+		if i := strings.LastIndex(value, " ("); i >= 0 && strings.HasSuffix(value, ")") {
+			value = value[:i]
+		}
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+
+		if summary == nil {
+			summary = make(map[string]string)
+		}
+		summary[tag] = value
+	}
+
+	return summary, hasGPS
+}
+
+// normalizeOutputFormat reports the "jpg" and "tif" spellings by their canonical names
+func normalizeOutputFormat(format string) string {
+	switch format {
+	case "jpg":
+		return "jpeg"
+	case "tif":
+		return "tiff"
+	default:
+		return format
+	}
+}
@@ -0,0 +1,86 @@
+package operations
+
+import (
+	"fmt"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// Device pixel ratio bounds
+const (
+	minDPR = 1
+	maxDPR = 4
+)
+
+// DPROperation handles dpr(ratio) filter.
+// It multiplies the requested width and height so templates can ask for the CSS size
+// and get an image for high-density screens.
+type DPROperation struct {
+	Ratio float64
+}
+
+func NewDPROperation() *DPROperation {
+	return &DPROperation{
+		Ratio: 1,
+	}
+}
+
+func (o *DPROperation) Name() string {
+	return "dpr"
+}
+
+func (o *DPROperation) Aliases() []string {
+	return []string{}
+}
+
+func (o *DPROperation) Clone() Operation {
+	return NewDPROperation()
+}
+
+func (o *DPROperation) Parse(filter string) (bool, error) {
+	if !matchesFilter(filter, o.Name(), o.Aliases()) {
+		return false, nil
+	}
+
+	args, err := filterArgs(filter, o.Name())
+	if err != nil {
+		return false, err
+	}
+	if len(args) != 1 {
+		return false, fmt.Errorf("dpr filter expects 1 parameter (ratio), got %d", len(args))
+	}
+
+	ratio, err := parseFloatArg("dpr ratio", args[0])
+	if err != nil {
+		return false, err
+	}
+
+	o.Ratio = ratio
+	return true, nil
+}
+
+// Validate checks that the ratio is within the supported range
+func (o *DPROperation) Validate() error {
+	if o.Ratio < minDPR || o.Ratio > maxDPR {
+		return fmt.Errorf("dpr must be between %d and %d, got: %g", minDPR, maxDPR, o.Ratio)
+	}
+	return nil
+}
+
+// Scale multiplies the resize dimensions by the ratio, rounding to whole pixels.
+// The scaled size is then checked against the resize limits like any other size.
+func (o *DPROperation) Scale(resize *ResizeOperation) {
+	if resize.Width != nil {
+		width := int(float64(*resize.Width)*o.Ratio + 0.5)
+		resize.Width = &width
+	}
+	if resize.Height != nil {
+		height := int(float64(*resize.Height)*o.Ratio + 0.5)
+		resize.Height = &height
+	}
+}
+
+func (o *DPROperation) Apply(img *vips.Image) (*vips.Image, error) {
+	// DPR is applied by scaling ResizeOperation dimensions in the parser
+	return img, nil
+}
@@ -0,0 +1,67 @@
+package operations
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// EffortOperation handles effort(level) filter.
+// It trades encoding CPU time for smaller files in WebP, AVIF, HEIC, JPEG XL and palette PNG/GIF.
+type EffortOperation struct {
+	Effort int
+}
+
+func NewEffortOperation() *EffortOperation {
+	return &EffortOperation{}
+}
+
+func (o *EffortOperation) Name() string {
+	return "effort"
+}
+
+func (o *EffortOperation) Aliases() []string {
+	return []string{}
+}
+
+func (o *EffortOperation) Clone() Operation {
+	return NewEffortOperation()
+}
+
+func (o *EffortOperation) Parse(filter string) (bool, error) {
+	if !matchesFilter(filter, o.Name(), o.Aliases()) {
+		return false, nil
+	}
+
+	args, err := filterArgs(filter, o.Name())
+	if err != nil {
+		return false, err
+	}
+	if len(args) != 1 {
+		return false, fmt.Errorf("effort filter expects 1 parameter (level), got %d", len(args))
+	}
+
+	if o.Effort, err = strconv.Atoi(args[0]); err != nil {
+		return false, fmt.Errorf("effort must be an integer, got: %s", args[0])
+	}
+	return true, nil
+}
+
+// Validate checks the effort range
+func (o *EffortOperation) Validate() error {
+	if o.Effort < minEffort || o.Effort > maxEffort {
+		return fmt.Errorf("effort must be between %d and %d, got: %d", minEffort, maxEffort, o.Effort)
+	}
+	return nil
+}
+
+// ApplyEncoding overrides the server default
+func (o *EffortOperation) ApplyEncoding(options *EncodeOptions) {
+	options.Effort = o.Effort
+}
+
+func (o *EffortOperation) Apply(img *vips.Image) (*vips.Image, error) {
+	// Effort is applied during export
+	return img, nil
+}
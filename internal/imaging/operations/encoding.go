@@ -0,0 +1,85 @@
+package operations
+
+import (
+	"fmt"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// Chroma subsampling modes accepted by subsampling(...)
+const (
+	SubsamplingAuto = "auto" // libvips decides, 4:2:0 below quality 90
+	Subsampling444  = "444"  // Full chroma resolution
+	Subsampling420  = "420"  // Chroma halved in both directions
+)
+
+// Encoder effort bounds. libvips treats 0 as "not set", so effort starts at 1.
+const (
+	minEffort     = 1
+	maxEffort     = 9
+	maxWebPEffort = 6
+)
+
+// EncodeOptions holds encoder tuning passed to the libvips savers. Options that do not apply
+// to the output format are ignored, so they can be combined with format(auto).
+// Zero values keep the libvips defaults.
+type EncodeOptions struct {
+	Progressive bool   // Progressive JPEG, interlaced PNG and GIF
+	Optimize    bool   // JPEG trellis quantisation and optimised Huffman coding (mozjpeg)
+	Subsampling string // Chroma subsampling for JPEG, AVIF and HEIC: auto, 444 or 420
+	Lossless    bool   // Lossless WebP, AVIF, HEIC and JPEG XL
+	Palette     bool   // Quantise PNG to an 8-bit palette, quality then controls the quantiser
+	Effort      int    // CPU effort 1..9 from effort(), 0 uses the per-format defaults below
+	WebPEffort  int    // Server default WebP effort 1..6, 0 keeps the libvips default
+	AVIFEffort  int    // Server default AVIF and HEIC effort 1..9, 0 keeps the libvips default
+}
+
+// Validate checks subsampling and effort ranges
+func (e EncodeOptions) Validate() error {
+	switch e.Subsampling {
+	case "", SubsamplingAuto, Subsampling444, Subsampling420:
+	default:
+		return fmt.Errorf("unsupported subsampling: %s (supported: auto, 444, 420)", e.Subsampling)
+	}
+	if e.Effort != 0 && (e.Effort < minEffort || e.Effort > maxEffort) {
+		return fmt.Errorf("effort must be between %d and %d, got: %d", minEffort, maxEffort, e.Effort)
+	}
+	if e.WebPEffort != 0 && (e.WebPEffort < minEffort || e.WebPEffort > maxWebPEffort) {
+		return fmt.Errorf("WebP effort must be between %d and %d, got: %d", minEffort, maxWebPEffort, e.WebPEffort)
+	}
+	if e.AVIFEffort != 0 && (e.AVIFEffort < minEffort || e.AVIFEffort > maxEffort) {
+		return fmt.Errorf("AVIF effort must be between %d and %d, got: %d", minEffort, maxEffort, e.AVIFEffort)
+	}
+	return nil
+}
+
+// effortFor returns the effort for an output format, 0 keeping the libvips default.
+// WebP accepts at most 6, so higher values are capped.
+func (e EncodeOptions) effortFor(format string) int {
+	switch format {
+	case "webp":
+		if e.Effort > 0 {
+			return min(e.Effort, maxWebPEffort)
+		}
+		return e.WebPEffort
+	case "avif", "heic":
+		if e.Effort > 0 {
+			return e.Effort
+		}
+		return e.AVIFEffort
+	default:
+		return e.Effort
+	}
+}
+
+// subsampleMode maps the subsampling setting to the libvips mode
+func (e EncodeOptions) subsampleMode() vips.Subsample {
+	switch e.Subsampling {
+	case Subsampling444:
+		return vips.SubsampleOff
+	case Subsampling420:
+		return vips.SubsampleOn
+	default:
+		return vips.SubsampleAuto
+	}
+}
@@ -7,10 +7,33 @@ import (
 	"github.com/cshum/vipsgen/vips"
 )
 
-// FitOperation handles fit(mode[,color]) filter
+// Fit modes accepted by fit(...)
+const (
+	FitCover   = "cover"   // Scale to cover the box, crop the excess
+	FitFill    = "fill"    // Scale to fit inside the box, pad the rest with a fill color
+	FitInside  = "inside"  // Scale to fit inside the box, no padding - output may be smaller
+	FitOutside = "outside" // Scale to cover the box, no cropping - output may be larger
+	FitStretch = "stretch" // Scale each axis independently, ignoring the aspect ratio
+)
+
+// fitModes maps accepted spellings to canonical fit modes
+var fitModes = map[string]string{
+	FitCover:   FitCover,
+	FitFill:    FitFill,
+	"contain":  FitFill,
+	FitInside:  FitInside,
+	FitOutside: FitOutside,
+	FitStretch: FitStretch,
+}
+
+// noUpscaleFlag is the fit(...) argument that prevents enlarging small sources
+const noUpscaleFlag = "noupscale"
+
+// FitOperation handles fit(mode[,color][,noupscale]) filter
 type FitOperation struct {
 	Mode      string
-	FillColor string
+	FillColor Color
+	NoUpscale bool
 	Format    string // Set during validation to check transparent compatibility
 }
 
@@ -35,36 +58,46 @@ func (o *FitOperation) Parse(filter string) (bool, error) {
 		return false, nil
 	}
 
-	if !strings.HasSuffix(filter, ")") {
-		return false, fmt.Errorf("fit filter missing closing parenthesis")
+	parts, err := filterArgs(filter, o.Name())
+	if err != nil {
+		return false, err
 	}
-
-	content := filter[strings.Index(filter, "(")+1 : len(filter)-1]
-	parts := strings.Split(content, ",")
-	if len(parts) == 0 || len(parts) > 2 {
-		return false, fmt.Errorf("fit filter expects 1 or 2 parameters, got: %s", content)
+	if len(parts) == 0 || len(parts) > 3 {
+		return false, fmt.Errorf("fit filter expects 1 to 3 parameters, got: %s", strings.Join(parts, ","))
 	}
 
-	fitMode := strings.TrimSpace(strings.ToLower(parts[0]))
-	if fitMode != "fill" && fitMode != "cover" {
-		return false, fmt.Errorf("fit mode must be 'fill' or 'cover', got: %s", fitMode)
+	fitMode, ok := fitModes[strings.ToLower(parts[0])]
+	if !ok {
+		return false, fmt.Errorf("fit mode must be 'cover', 'fill', 'contain', 'inside', 'outside' or 'stretch', got: %s", parts[0])
 	}
 
 	o.Mode = fitMode
-	o.FillColor = "white" // Default
+	o.FillColor = ColorWhite // Default
+	o.NoUpscale = false
+
+	hasColor := false
+	for _, arg := range parts[1:] {
+		if strings.ToLower(arg) == noUpscaleFlag {
+			if o.NoUpscale {
+				return false, fmt.Errorf("fit filter has duplicate %s flag", noUpscaleFlag)
+			}
+			o.NoUpscale = true
+			continue
+		}
 
-	if len(parts) == 2 {
-		if fitMode != "fill" {
+		if fitMode != FitFill {
 			return false, fmt.Errorf("color parameter is only valid for fit(fill), not fit(%s)", fitMode)
 		}
+		if hasColor || o.NoUpscale {
+			return false, fmt.Errorf("fit filter expects fit(fill[,color][,%s]), got: %s", noUpscaleFlag, strings.Join(parts, ","))
+		}
 
-		color := strings.TrimSpace(strings.ToLower(parts[1]))
-		switch color {
-		case "black", "white", "transparent":
-			o.FillColor = color
-		default:
-			return false, fmt.Errorf("fill color must be 'black', 'white', or 'transparent', got: %s", color)
+		color, err := ParseColor(arg)
+		if err != nil {
+			return false, fmt.Errorf("invalid fill color: %w", err)
 		}
+		o.FillColor = color
+		hasColor = true
 	}
 
 	return true, nil
@@ -76,13 +109,17 @@ func (o *FitOperation) Apply(img *vips.Image) (*vips.Image, error) {
 	return img, nil
 }
 
-// Validate checks that transparent fill color is only used with PNG, WebP, or AVIF formats
+// SetOutputFormat records the output format for transparency validation
+func (o *FitOperation) SetOutputFormat(format string) {
+	o.Format = format
+}
+
+// Validate checks that transparent or translucent fill colors are only used with formats that
+// carry alpha. GIF accepts fully transparent fills only, as its transparency is on/off.
+// format(auto) is accepted because it falls back to PNG for images with alpha.
 func (o *FitOperation) Validate() error {
-	if o.FillColor == "transparent" {
-		// Transparent requires PNG, WebP, or AVIF format
-		if o.Format != "png" && o.Format != "webp" && o.Format != "avif" {
-			return fmt.Errorf("transparent fill color requires PNG, WebP, or AVIF format (use filters:format(png), filters:format(webp), or filters:format(avif))")
-		}
+	if o.Mode == FitFill && !o.FillColor.IsOpaque() {
+		return checkAlphaFormat("transparent fill color", o.Format, o.FillColor.A)
 	}
 	return nil
 }
@@ -0,0 +1,71 @@
+package operations
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// FlipOperation handles flip(h|v) filter
+type FlipOperation struct {
+	Direction string // "h" mirrors left-right, "v" mirrors top-bottom
+}
+
+func NewFlipOperation() *FlipOperation {
+	return &FlipOperation{}
+}
+
+func (o *FlipOperation) Name() string {
+	return "flip"
+}
+
+func (o *FlipOperation) Aliases() []string {
+	return []string{}
+}
+
+func (o *FlipOperation) Clone() Operation {
+	return NewFlipOperation()
+}
+
+// Stage runs flip before crop, so crop coordinates refer to the flipped image
+func (o *FlipOperation) Stage() Stage {
+	return StageOrientation
+}
+
+func (o *FlipOperation) Parse(filter string) (bool, error) {
+	if !matchesFilter(filter, o.Name(), o.Aliases()) {
+		return false, nil
+	}
+
+	args, err := filterArgs(filter, o.Name())
+	if err != nil {
+		return false, err
+	}
+	if len(args) != 1 {
+		return false, fmt.Errorf("flip filter expects 1 parameter (h or v), got %d", len(args))
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "h", "horizontal":
+		o.Direction = "h"
+	case "v", "vertical":
+		o.Direction = "v"
+	default:
+		return false, fmt.Errorf("flip direction must be 'h' or 'v', got: %s", args[0])
+	}
+
+	return true, nil
+}
+
+func (o *FlipOperation) Apply(img *vips.Image) (*vips.Image, error) {
+	direction := vips.DirectionHorizontal
+	if o.Direction == "v" {
+		direction = vips.DirectionVertical
+	}
+
+	if err := img.Flip(direction); err != nil {
+		return nil, fmt.Errorf("failed to flip image: %w", err)
+	}
+	return img, nil
+}
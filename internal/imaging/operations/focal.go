@@ -0,0 +1,78 @@
+package operations
+
+import (
+	"fmt"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// FocalPoint is the point cover-mode resize keeps as centred as possible.
+// Coordinates refer to the image as it enters resize (after rotate, flip and crop).
+type FocalPoint struct {
+	X, Y    float64
+	Percent bool // X and Y are percentages (0-100) instead of pixels
+}
+
+// FocalOperation handles focal(x,y) filter with pixel coordinates
+type FocalOperation struct {
+	X, Y int
+}
+
+func NewFocalOperation() *FocalOperation {
+	return &FocalOperation{}
+}
+
+func (o *FocalOperation) Name() string {
+	return "focal"
+}
+
+func (o *FocalOperation) Aliases() []string {
+	return []string{"fp"}
+}
+
+func (o *FocalOperation) Clone() Operation {
+	return NewFocalOperation()
+}
+
+func (o *FocalOperation) Parse(filter string) (bool, error) {
+	if !matchesFilter(filter, o.Name(), o.Aliases()) {
+		return false, nil
+	}
+
+	args, err := filterArgs(filter, o.Name())
+	if err != nil {
+		return false, err
+	}
+	if len(args) != 2 {
+		return false, fmt.Errorf("focal filter expects 2 coordinates (x,y), got %d", len(args))
+	}
+
+	coords := make([]int, 2)
+	for i, arg := range args {
+		if arg == "" {
+			return false, fmt.Errorf("focal coordinate %d is empty", i+1)
+		}
+
+		num := 0
+		for _, ch := range arg {
+			if ch < '0' || ch > '9' {
+				return false, fmt.Errorf("focal coordinate %d must be a positive integer, got: %s", i+1, arg)
+			}
+			num = num*10 + int(ch-'0')
+		}
+		coords[i] = num
+	}
+
+	o.X, o.Y = coords[0], coords[1]
+	return true, nil
+}
+
+func (o *FocalOperation) Apply(img *vips.Image) (*vips.Image, error) {
+	// Focal point is applied by modifying ResizeOperation behavior
+	return img, nil
+}
+
+// FocalPoint returns the focal point for ResizeOperation
+func (o *FocalOperation) FocalPoint() *FocalPoint {
+	return &FocalPoint{X: float64(o.X), Y: float64(o.Y)}
+}
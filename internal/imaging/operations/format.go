@@ -2,14 +2,23 @@ package operations
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/cshum/vipsgen/vips"
 )
 
+// FormatAuto selects the output format per request from the Accept header.
+const FormatAuto = "auto"
+
 // FormatOperation handles format(type) filter
 type FormatOperation struct {
-	Format string
+	Format   string
+	Auto     bool      // True for format(auto); Format is then resolved by Negotiate
+	Keep     vips.Keep // Metadata kept on export, set from MetadataOperation (zero keeps libvips defaults)
+	Animated bool      // Set by ApplyAll when the image has several frames
+
+	Encoding EncodeOptions // Encoder tuning: server defaults from the registry prototype, overridden by URL filters
 }
 
 func NewFormatOperation() *FormatOperation {
@@ -26,8 +35,11 @@ func (o *FormatOperation) Aliases() []string {
 	return []string{"fmt"}
 }
 
+// Clone keeps the server encoding defaults of the prototype
 func (o *FormatOperation) Clone() Operation {
-	return NewFormatOperation()
+	clone := NewFormatOperation()
+	clone.Encoding = o.Encoding
+	return clone
 }
 
 func (o *FormatOperation) Parse(filter string) (bool, error) {
@@ -48,12 +60,65 @@ func (o *FormatOperation) Parse(filter string) (bool, error) {
 
 	format := strings.ToLower(content)
 	switch format {
-	case "webp", "jpeg", "png", "jpg", "avif":
+	case "webp", "jpeg", "png", "jpg", "avif", "jxl", "gif", "tiff", "tif", "heic":
 		o.Format = format
 		return true, nil
+	case FormatAuto:
+		o.SetAuto()
+		return true, nil
 	default:
-		return false, fmt.Errorf("unsupported format: %s (supported: webp, jpeg, png, avif)", format)
+		return false, fmt.Errorf("unsupported format: %s (supported: webp, jpeg, png, avif, jxl, gif, tiff, heic, auto)", format)
+	}
+}
+
+// SetAuto switches the operation to automatic format negotiation.
+func (o *FormatOperation) SetAuto() {
+	o.Format = FormatAuto
+	o.Auto = true
+}
+
+// Negotiate resolves format(auto) against the request Accept header.
+// AVIF is preferred over WebP; when neither is accepted Format stays "auto"
+// and Export picks PNG for images with alpha and JPEG otherwise.
+// Returns the negotiated variant name, or "" when the operation is not automatic.
+func (o *FormatOperation) Negotiate(accept string) string {
+	if !o.Auto {
+		return ""
+	}
+
+	switch {
+	case acceptsMediaType(accept, "image/avif"):
+		o.Format = "avif"
+	case acceptsMediaType(accept, "image/webp"):
+		o.Format = "webp"
+	default:
+		o.Format = FormatAuto
+	}
+	return o.Format
+}
+
+// acceptsMediaType reports whether an Accept header explicitly lists mediaType with a non-zero q value.
+// Wildcards are ignored: browsers send image/* even when they cannot decode modern formats.
+func acceptsMediaType(accept, mediaType string) bool {
+	for mediaRange := range strings.SplitSeq(accept, ",") {
+		params := strings.Split(mediaRange, ";")
+		if !strings.EqualFold(strings.TrimSpace(params[0]), mediaType) {
+			continue
+		}
+
+		for _, param := range params[1:] {
+			name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || !strings.EqualFold(strings.TrimSpace(name), "q") {
+				continue
+			}
+			q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || q <= 0 {
+				return false
+			}
+		}
+		return true
 	}
+	return false
 }
 
 func (o *FormatOperation) Apply(img *vips.Image) (*vips.Image, error) {
@@ -67,28 +132,82 @@ func (o *FormatOperation) Export(img *vips.Image, quality int) ([]byte, string,
 	var contentType string
 	var err error
 
-	switch o.Format {
+	format := o.outputFormat(img)
+	enc := o.Encoding
+	switch format {
 	case "webp":
 		result, err = img.WebpsaveBuffer(&vips.WebpsaveBufferOptions{
-			Q: quality,
+			Q:        quality,
+			Lossless: enc.Lossless,
+			Effort:   enc.effortFor(format),
+			Keep:     o.Keep,
 		})
 		contentType = "image/webp"
 	case "avif":
 		result, err = img.HeifsaveBuffer(&vips.HeifsaveBufferOptions{
-			Q:           quality,
-			Compression: vips.HeifCompressionAv1,
+			Q:             quality,
+			Compression:   vips.HeifCompressionAv1,
+			Lossless:      enc.Lossless,
+			Effort:        enc.effortFor(format),
+			SubsampleMode: enc.subsampleMode(),
+			Keep:          o.Keep,
 		})
 		contentType = "image/avif"
 	case "png":
 		result, err = img.PngsaveBuffer(&vips.PngsaveBufferOptions{
-			Q: quality,
+			Q:         quality,
+			Interlace: enc.Progressive,
+			Palette:   enc.Palette,
+			Effort:    enc.effortFor(format),
+			Keep:      o.Keep,
 		})
 		contentType = "image/png"
 	case "jpeg", "jpg":
 		result, err = img.JpegsaveBuffer(&vips.JpegsaveBufferOptions{
-			Q: quality,
+			Q:              quality,
+			Interlace:      enc.Progressive,
+			OptimizeCoding: enc.Optimize,
+			TrellisQuant:   enc.Optimize,
+			// Scan optimisation only applies to progressive JPEG
+			OptimizeScans: enc.Optimize && enc.Progressive,
+			SubsampleMode: enc.subsampleMode(),
+			Keep:          o.Keep,
 		})
 		contentType = "image/jpeg"
+	case "jxl":
+		result, err = img.JxlsaveBuffer(&vips.JxlsaveBufferOptions{
+			Q:        quality,
+			Lossless: enc.Lossless,
+			Effort:   enc.effortFor(format),
+			Keep:     o.Keep,
+		})
+		contentType = "image/jxl"
+	case "gif":
+		// GIF is palette-based, quality does not apply
+		result, err = img.GifsaveBuffer(&vips.GifsaveBufferOptions{
+			Interlace: enc.Progressive,
+			Effort:    enc.effortFor(format),
+			Keep:      o.Keep,
+		})
+		contentType = "image/gif"
+	case "tiff", "tif":
+		// Lossless deflate keeps TIFF small without touching quality or alpha
+		result, err = img.TiffsaveBuffer(&vips.TiffsaveBufferOptions{
+			Compression: vips.TiffCompressionDeflate,
+			Predictor:   vips.TiffPredictorHorizontal,
+			Keep:        o.Keep,
+		})
+		contentType = "image/tiff"
+	case "heic":
+		result, err = img.HeifsaveBuffer(&vips.HeifsaveBufferOptions{
+			Q:             quality,
+			Compression:   vips.HeifCompressionHevc,
+			Lossless:      enc.Lossless,
+			Effort:        enc.effortFor(format),
+			SubsampleMode: enc.subsampleMode(),
+			Keep:          o.Keep,
+		})
+		contentType = "image/heic"
 	default:
 		return nil, "", fmt.Errorf("unsupported format: %s", o.Format)
 	}
@@ -100,6 +219,40 @@ func (o *FormatOperation) Export(img *vips.Image, quality int) ([]byte, string,
 	return result, contentType, nil
 }
 
+// outputFormat returns the format Export encodes to, resolving format(auto) without a
+// negotiated modern format.
+func (o *FormatOperation) outputFormat(img *vips.Image) string {
+	if o.Format != FormatAuto {
+		return o.Format
+	}
+
+	// No modern format accepted by the client: keep animation and transparency when present
+	switch {
+	case o.Animated:
+		return "gif"
+	case img.HasAlpha():
+		return "png"
+	default:
+		return "jpeg"
+	}
+}
+
+// QualityApplies reports whether the quality setting changes the encoded output.
+// GIF and TIFF ignore it, PNG only uses it for palette quantisation, and lossless
+// encoders ignore it.
+func (o *FormatOperation) QualityApplies(img *vips.Image) bool {
+	switch o.outputFormat(img) {
+	case "gif", "tiff", "tif":
+		return false
+	case "png":
+		return o.Encoding.Palette
+	case "webp", "avif", "heic", "jxl":
+		return !o.Encoding.Lossless
+	default:
+		return true
+	}
+}
+
 // DetectFromExtension sets format based on file extension
 func (o *FormatOperation) DetectFromExtension(path string) {
 	ext := strings.ToLower(path)
@@ -0,0 +1,145 @@
+package operations
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// Gravity values accepted by gravity(...). Smart strategies let libvips pick the crop region;
+// compass directions pin the crop to an edge or corner.
+const (
+	GravityCentre    = "centre"
+	GravityAttention = "attention"
+	GravityEntropy   = "entropy"
+)
+
+// gravityAliases maps accepted spellings to canonical gravity names
+var gravityAliases = map[string]string{
+	"centre":     GravityCentre,
+	"center":     GravityCentre,
+	"attention":  GravityAttention,
+	"entropy":    GravityEntropy,
+	"north":      "north",
+	"n":          "north",
+	"south":      "south",
+	"s":          "south",
+	"east":       "east",
+	"e":          "east",
+	"west":       "west",
+	"w":          "west",
+	"north-east": "north-east",
+	"ne":         "north-east",
+	"north-west": "north-west",
+	"nw":         "north-west",
+	"south-east": "south-east",
+	"se":         "south-east",
+	"south-west": "south-west",
+	"sw":         "south-west",
+}
+
+// GravityOperation handles gravity(type) and smart([attention|entropy]) filters.
+// It selects which part of the image is kept when fit(cover) crops.
+type GravityOperation struct {
+	Gravity string
+}
+
+func NewGravityOperation() *GravityOperation {
+	return &GravityOperation{
+		Gravity: GravityCentre,
+	}
+}
+
+func (o *GravityOperation) Name() string {
+	return "gravity"
+}
+
+func (o *GravityOperation) Aliases() []string {
+	return []string{"g", "smart"}
+}
+
+func (o *GravityOperation) Clone() Operation {
+	return NewGravityOperation()
+}
+
+func (o *GravityOperation) Parse(filter string) (bool, error) {
+	if !matchesFilter(filter, o.Name(), o.Aliases()) {
+		return false, nil
+	}
+
+	args, err := filterArgs(filter, o.Name())
+	if err != nil {
+		return false, err
+	}
+
+	// smart() defaults to attention and only accepts smart strategies
+	if strings.HasPrefix(filter, "smart(") {
+		if len(args) == 0 {
+			o.Gravity = GravityAttention
+			return true, nil
+		}
+		if len(args) == 1 {
+			switch strings.ToLower(args[0]) {
+			case GravityAttention, GravityEntropy:
+				o.Gravity = strings.ToLower(args[0])
+				return true, nil
+			}
+		}
+		return false, fmt.Errorf("smart filter expects no parameter, 'attention' or 'entropy', got: %s", strings.Join(args, ","))
+	}
+
+	if len(args) != 1 {
+		return false, fmt.Errorf("gravity filter expects 1 parameter, got %d", len(args))
+	}
+
+	gravity, ok := gravityAliases[strings.ToLower(args[0])]
+	if !ok {
+		return false, fmt.Errorf("unsupported gravity: %s (supported: centre, attention, entropy, north, south, east, west, north-east, north-west, south-east, south-west)", args[0])
+	}
+
+	o.Gravity = gravity
+	return true, nil
+}
+
+func (o *GravityOperation) Apply(img *vips.Image) (*vips.Image, error) {
+	// Gravity is applied by modifying ResizeOperation behavior
+	return img, nil
+}
+
+// isSmartGravity reports whether libvips should choose the crop region itself
+func isSmartGravity(gravity string) bool {
+	return gravity == "" || gravity == GravityCentre || gravity == GravityAttention || gravity == GravityEntropy
+}
+
+// interestingFor maps a smart gravity to the libvips crop strategy
+func interestingFor(gravity string) vips.Interesting {
+	switch gravity {
+	case GravityAttention:
+		return vips.InterestingAttention
+	case GravityEntropy:
+		return vips.InterestingEntropy
+	default:
+		return vips.InterestingCentre
+	}
+}
+
+// gravityOffset returns the crop origin for a compass gravity, given how many pixels
+// the resized image exceeds the target box by in each direction.
+func gravityOffset(gravity string, extraWidth, extraHeight int) (left, top int) {
+	left, top = extraWidth/2, extraHeight/2
+
+	if strings.HasPrefix(gravity, "north") {
+		top = 0
+	} else if strings.HasPrefix(gravity, "south") {
+		top = extraHeight
+	}
+
+	if strings.HasSuffix(gravity, "west") {
+		left = 0
+	} else if strings.HasSuffix(gravity, "east") {
+		left = extraWidth
+	}
+
+	return left, top
+}
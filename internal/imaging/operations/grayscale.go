@@ -0,0 +1,61 @@
+package operations
+
+import (
+	"fmt"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// GrayscaleOperation handles grayscale() filter
+type GrayscaleOperation struct{}
+
+func NewGrayscaleOperation() *GrayscaleOperation {
+	return &GrayscaleOperation{}
+}
+
+func (o *GrayscaleOperation) Name() string {
+	return "grayscale"
+}
+
+func (o *GrayscaleOperation) Aliases() []string {
+	return []string{"gs", "greyscale"}
+}
+
+func (o *GrayscaleOperation) Clone() Operation {
+	return NewGrayscaleOperation()
+}
+
+func (o *GrayscaleOperation) Stage() Stage {
+	return StagePostResize
+}
+
+func (o *GrayscaleOperation) Parse(filter string) (bool, error) {
+	if !matchesFilter(filter, o.Name(), o.Aliases()) {
+		return false, nil
+	}
+
+	args, err := filterArgs(filter, o.Name())
+	if err != nil {
+		return false, err
+	}
+	if len(args) != 0 {
+		return false, fmt.Errorf("grayscale filter expects no parameters, got %d", len(args))
+	}
+
+	return true, nil
+}
+
+func (o *GrayscaleOperation) Apply(img *vips.Image) (*vips.Image, error) {
+	space := vips.InterpretationBW
+	switch img.Interpretation() {
+	case vips.InterpretationBW, vips.InterpretationGrey16:
+		return img, nil
+	case vips.InterpretationRgb16:
+		space = vips.InterpretationGrey16
+	}
+
+	if err := img.Colourspace(space, nil); err != nil {
+		return nil, fmt.Errorf("failed to convert image to grayscale: %w", err)
+	}
+	return img, nil
+}
@@ -0,0 +1,58 @@
+package operations
+
+import (
+	"fmt"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// MaskShape is the outline produced by radius() or circle(). border() follows it,
+// so outlines stay inside the visible area.
+type MaskShape struct {
+	Circle  bool    // Centred circle with the diameter of the shorter side
+	Radius  float64 // Corner radius, in pixels or percent of the shorter side
+	Percent bool    // Radius is a percentage
+}
+
+// cornerRadius returns the corner radius in pixels for a width x height image
+func (s MaskShape) cornerRadius(width, height int) float64 {
+	shorter := float64(min(width, height))
+	radius := s.Radius
+	if s.Percent {
+		radius = s.Radius * shorter / 100
+	}
+	return min(radius, shorter/2)
+}
+
+// svgElement returns the shape as an SVG element inset by inset pixels on every side
+func (s MaskShape) svgElement(width, height int, inset float64, attrs string) string {
+	if s.Circle {
+		radius := float64(min(width, height))/2 - inset
+		return fmt.Sprintf(`<circle cx="%g" cy="%g" r="%g" %s/>`, float64(width)/2, float64(height)/2, max(radius, 0), attrs)
+	}
+
+	radius := max(s.cornerRadius(width, height)-inset, 0)
+	return fmt.Sprintf(`<rect x="%g" y="%g" width="%g" height="%g" rx="%g" ry="%g" %s/>`,
+		inset, inset, max(float64(width)-2*inset, 0), max(float64(height)-2*inset, 0), radius, radius, attrs)
+}
+
+// renderShape rasterises the shape into an RGBA image of the given size
+func renderShape(width, height int, shape MaskShape, inset float64, attrs string) (*vips.Image, error) {
+	svg := fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d">%s</svg>`,
+		width, height, shape.svgElement(width, height, inset, attrs))
+	return vips.NewSvgloadBuffer([]byte(svg), nil)
+}
+
+// applyMask makes everything outside the shape transparent, keeping any existing alpha
+func applyMask(img *vips.Image, shape MaskShape) error {
+	mask, err := renderShape(img.Width(), img.Height(), shape, 0, `fill="#fff"`)
+	if err != nil {
+		return fmt.Errorf("failed to render mask: %w", err)
+	}
+	defer mask.Close()
+
+	if err := img.Composite2(mask, vips.BlendModeDestIn, vips.DefaultComposite2Options()); err != nil {
+		return fmt.Errorf("failed to apply mask: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package operations
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// Quality floor used by maxbytes when no minimum quality is given
+const defaultMinQuality = 10
+
+// MaxBytesOperation handles maxbytes(bytes[,min_quality]) filter.
+// Export searches for the highest quality, up to quality(...), whose output fits the budget.
+type MaxBytesOperation struct {
+	Bytes      int
+	MinQuality int
+
+	Quality int // Quality of the exported image, set by Export (0 when quality does not apply)
+}
+
+func NewMaxBytesOperation() *MaxBytesOperation {
+	return &MaxBytesOperation{
+		MinQuality: defaultMinQuality,
+	}
+}
+
+func (o *MaxBytesOperation) Name() string {
+	return "maxbytes"
+}
+
+func (o *MaxBytesOperation) Aliases() []string {
+	return []string{}
+}
+
+func (o *MaxBytesOperation) Clone() Operation {
+	return NewMaxBytesOperation()
+}
+
+func (o *MaxBytesOperation) Parse(filter string) (bool, error) {
+	if !matchesFilter(filter, o.Name(), o.Aliases()) {
+		return false, nil
+	}
+
+	args, err := filterArgs(filter, o.Name())
+	if err != nil {
+		return false, err
+	}
+	if len(args) == 0 || len(args) > 2 {
+		return false, fmt.Errorf("maxbytes filter expects 1 or 2 parameters (bytes,min_quality), got %d", len(args))
+	}
+
+	if o.Bytes, err = parsePositiveInt(strings.TrimSpace(args[0])); err != nil {
+		return false, fmt.Errorf("invalid maxbytes size: %w", err)
+	}
+
+	if len(args) > 1 && args[1] != "" {
+		if o.MinQuality, err = parsePositiveInt(args[1]); err != nil {
+			return false, fmt.Errorf("invalid maxbytes minimum quality: %w", err)
+		}
+	}
+
+	return true, nil
+}
+
+// Validate checks the minimum quality range
+func (o *MaxBytesOperation) Validate() error {
+	if o.MinQuality < 1 || o.MinQuality > 100 {
+		return fmt.Errorf("maxbytes minimum quality must be between 1 and 100, got: %d", o.MinQuality)
+	}
+	return nil
+}
+
+func (o *MaxBytesOperation) Apply(img *vips.Image) (*vips.Image, error) {
+	// The byte budget is applied during export
+	return img, nil
+}
+
+// Export encodes the image at maxQuality and, if the output exceeds the budget, binary searches
+// down to MinQuality for the highest quality that fits. When even MinQuality does not fit,
+// the smallest output found is returned.
+func (o *MaxBytesOperation) Export(img *vips.Image, format *FormatOperation, maxQuality int) ([]byte, string, error) {
+	data, contentType, err := format.Export(img, maxQuality)
+	if err != nil {
+		return nil, "", err
+	}
+	if !format.QualityApplies(img) {
+		return data, contentType, nil
+	}
+
+	o.Quality = maxQuality
+	if len(data) <= o.Bytes {
+		return data, contentType, nil
+	}
+
+	// Invariant: qualities above high are known not to fit
+	low, high := min(o.MinQuality, maxQuality), maxQuality-1
+	var fitting []byte
+	for low <= high {
+		quality := (low + high) / 2
+		candidate, _, err := format.Export(img, quality)
+		if err != nil {
+			return nil, "", err
+		}
+
+		switch {
+		case len(candidate) <= o.Bytes:
+			fitting, o.Quality = candidate, quality
+			low = quality + 1
+		case fitting == nil && len(candidate) < len(data):
+			// Keep the smallest output in case nothing fits
+			data, o.Quality = candidate, quality
+			high = quality - 1
+		default:
+			high = quality - 1
+		}
+	}
+
+	if fitting != nil {
+		return fitting, contentType, nil
+	}
+	return data, contentType, nil
+}
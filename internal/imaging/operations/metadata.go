@@ -0,0 +1,141 @@
+package operations
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// Metadata kinds accepted by keep(...)
+const (
+	MetadataNone = "none" // keep(none) is the same as strip()
+	MetadataICC  = "icc"
+	MetadataEXIF = "exif"
+	MetadataXMP  = "xmp"
+	MetadataIPTC = "iptc"
+	MetadataAll  = "all"
+)
+
+// metadataKeepFlags maps metadata kinds to libvips save flags
+var metadataKeepFlags = map[string]vips.Keep{
+	MetadataICC:  vips.KeepIcc,
+	MetadataEXIF: vips.KeepExif,
+	MetadataXMP:  vips.KeepXmp,
+	MetadataIPTC: vips.KeepIptc,
+	MetadataAll:  vips.KeepAll,
+}
+
+// MetadataOperation handles strip() and keep(icc|exif|xmp|iptc|all[,...]) filters.
+// It selects which metadata survives export, so EXIF/XMP (including GPS) is not leaked
+// by default libvips save options.
+type MetadataOperation struct {
+	Keep []string // Metadata kinds to keep, empty strips everything
+}
+
+func NewMetadataOperation() *MetadataOperation {
+	return &MetadataOperation{}
+}
+
+func (o *MetadataOperation) Name() string {
+	return "metadata"
+}
+
+func (o *MetadataOperation) Aliases() []string {
+	return []string{"strip", "keep"}
+}
+
+func (o *MetadataOperation) Clone() Operation {
+	return NewMetadataOperation()
+}
+
+func (o *MetadataOperation) Parse(filter string) (bool, error) {
+	if !matchesFilter(filter, o.Name(), o.Aliases()) {
+		return false, nil
+	}
+
+	args, err := filterArgs(filter, o.Name())
+	if err != nil {
+		return false, err
+	}
+
+	if strings.HasPrefix(filter, "strip(") {
+		if len(args) != 0 {
+			return false, fmt.Errorf("strip filter expects no parameters, got %d", len(args))
+		}
+		o.Keep = nil
+		return true, nil
+	}
+
+	if len(args) == 0 {
+		return false, fmt.Errorf("keep filter requires at least one of: none, icc, exif, xmp, iptc, all")
+	}
+	if o.Keep, err = ParseMetadataKeep(args); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ParseMetadataKeep validates metadata kinds and normalizes them. "none" yields an empty list.
+func ParseMetadataKeep(values []string) ([]string, error) {
+	var keep []string
+	for _, value := range values {
+		kind := strings.ToLower(strings.TrimSpace(value))
+		if kind == MetadataNone {
+			if len(values) > 1 {
+				return nil, fmt.Errorf("metadata kind 'none' cannot be combined with other kinds")
+			}
+			return nil, nil
+		}
+		if _, ok := metadataKeepFlags[kind]; !ok {
+			return nil, fmt.Errorf("unsupported metadata kind: %s (supported: none, icc, exif, xmp, iptc, all)", value)
+		}
+		if !slices.Contains(keep, kind) {
+			keep = append(keep, kind)
+		}
+	}
+	return keep, nil
+}
+
+// KeepFlags returns the libvips save flags for the kept metadata
+func (o *MetadataOperation) KeepFlags() vips.Keep {
+	if len(o.Keep) == 0 {
+		return vips.KeepNone
+	}
+
+	var flags vips.Keep
+	for _, kind := range o.Keep {
+		flags |= metadataKeepFlags[kind]
+	}
+	return flags
+}
+
+// KeepsEXIF reports whether EXIF survives export
+func (o *MetadataOperation) KeepsEXIF() bool {
+	return slices.Contains(o.Keep, MetadataEXIF) || slices.Contains(o.Keep, MetadataAll)
+}
+
+// Apply converts the image to sRGB unless all metadata is kept. Colours in a custom
+// profile are only correct while the profile is present, so they are converted before
+// the profile is dropped. With keep(icc) the sRGB profile is embedded.
+func (o *MetadataOperation) Apply(img *vips.Image) (*vips.Image, error) {
+	if slices.Contains(o.Keep, MetadataAll) {
+		return img, nil
+	}
+
+	keepICC := slices.Contains(o.Keep, MetadataICC)
+	if !keepICC && !img.HasICCProfile() && img.Interpretation() != vips.InterpretationCmyk {
+		return img, nil
+	}
+
+	options := vips.DefaultIccTransformOptions()
+	options.Embedded = true
+	if img.Interpretation() == vips.InterpretationRgb16 {
+		options.Depth = 16
+	}
+	if err := img.IccTransform("srgb", options); err != nil {
+		return nil, fmt.Errorf("failed to convert image to sRGB: %w", err)
+	}
+	return img, nil
+}
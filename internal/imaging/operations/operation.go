@@ -1,6 +1,9 @@
 package operations
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/cshum/vipsgen/vips"
@@ -24,12 +27,14 @@ func matchesFilter(filter, name string, aliases []string) bool {
 type Request struct {
 	Path              string // Image path in storage
 	Alias             string // Optional output alias filename from /as/{alias}
-	AliasExtension    string // Optional normalized alias extension (jpeg/png/webp/avif)
+	AliasExtension    string // Optional normalized alias extension (jpeg/png/webp/avif/jxl/gif/tiff/heic)
 	HasAlias          bool   // True when URL contains /as/{alias}
 	ProvidedSignature string // URL signature for validation
 	SignaturePayload  string // Canonical payload to sign/verify: /{size}/[filters:{filters}/]{path}[/as/{alias.ext}]
 	FilterString      string // Raw filter string for signature validation
 	RawURLPath        string // Raw path after /thumbs/ (without query params) for signature validation
+	Preset            string // Optional preset name from p:{name} size segment or preset(name) filter
+	PresetHash        string // Hash of the preset definition, so cached output changes when the preset is edited
 
 	// Parsed operations to apply (in order)
 	Operations []Operation
@@ -61,3 +66,120 @@ type Operation interface {
 type Validatable interface {
 	Validate() error
 }
+
+// FormatDependent is an optional interface for operations whose validation depends on the
+// output format (e.g. transparent backgrounds). The parser calls SetOutputFormat before Validate.
+type FormatDependent interface {
+	SetOutputFormat(format string)
+}
+
+// EncoderOption is an optional interface for operations that tune the output encoder
+// (e.g. progressive(), effort(3)). The parser applies them to the FormatOperation.
+type EncoderOption interface {
+	ApplyEncoding(options *EncodeOptions)
+}
+
+// ResourceLoader fetches auxiliary images referenced by operations (e.g. watermarks).
+// drivers.Storage satisfies it, so resources go through the same storage and source cache.
+type ResourceLoader interface {
+	GetObject(ctx context.Context, key string) ([]byte, error)
+}
+
+// ResourceUser is an optional interface for operations that need external resources.
+// ApplyAll calls LoadResources before decoding the source image, so Apply can stay synchronous.
+type ResourceUser interface {
+	LoadResources(ctx context.Context, loader ResourceLoader) error
+}
+
+// alphaFormats lists the output formats that carry transparency, in the order used by
+// validation errors. GIF has 1-bit transparency, so it only accepts fully transparent pixels.
+var alphaFormats = []struct {
+	format  string
+	name    string
+	partial bool // Supports translucent pixels, not only fully transparent ones
+}{
+	{"png", "PNG", true},
+	{"webp", "WebP", true},
+	{"avif", "AVIF", true},
+	{"jxl", "JPEG XL", true},
+	{"tiff", "TIFF", true},
+	{"heic", "HEIC", true},
+	{"gif", "GIF", false},
+}
+
+// formatSupportsAlpha reports whether an output format can carry the given alpha value.
+// "auto" qualifies because it falls back to PNG for images with alpha.
+func formatSupportsAlpha(format string, alpha uint8) bool {
+	switch format {
+	case FormatAuto:
+		return true
+	case "tif":
+		format = "tiff"
+	}
+
+	for _, f := range alphaFormats {
+		if f.format == format {
+			return f.partial || alpha == 0
+		}
+	}
+	return false
+}
+
+// checkAlphaFormat returns a validation error naming the formats that can carry the given
+// alpha value when the output format cannot. subject describes what needs transparency.
+func checkAlphaFormat(subject, format string, alpha uint8) error {
+	if formatSupportsAlpha(format, alpha) {
+		return nil
+	}
+
+	var names, hints []string
+	for _, f := range alphaFormats {
+		if f.partial || alpha == 0 {
+			names = append(names, f.name)
+			hints = append(hints, "filters:format("+f.format+")")
+		}
+	}
+
+	msg := fmt.Sprintf("%s requires %s format (use %s)", subject, joinOr(names), joinOr(hints))
+	if format == "gif" {
+		msg += "; GIF supports only fully transparent colors"
+	}
+	return errors.New(msg)
+}
+
+// joinOr joins items as "a, b, or c"
+func joinOr(items []string) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + ", or " + items[len(items)-1]
+}
+
+// Stage identifies when an operation runs relative to resize in ApplyAll.
+type Stage int
+
+const (
+	// StageOrientation runs first, so crop coordinates refer to the rotated/flipped image.
+	StageOrientation Stage = iota
+	// StageProcess runs before resize on the source image (crop, fit, ...). Default stage.
+	StageProcess
+	// StagePostResize runs after resize on the output-sized image. Effects sized in output
+	// pixels belong here, and so do per-pixel colour adjustments (brightness, contrast,
+	// saturation, gamma, grayscale, tint), as the output usually has far fewer pixels.
+	StagePostResize
+	// StageOverlay runs last, after effects, so overlays are neither scaled nor blurred.
+	StageOverlay
+)
+
+// Staged is an optional interface for operations that do not run in StageProcess.
+type Staged interface {
+	Stage() Stage
+}
+
+// stageOf returns the stage an operation runs in
+func stageOf(op Operation) Stage {
+	if staged, ok := op.(Staged); ok {
+		return staged.Stage()
+	}
+	return StageProcess
+}
@@ -0,0 +1,69 @@
+package operations
+
+import (
+	"fmt"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// PercentFocalOperation handles pfocal(x,y) filter where coordinates are 0-100 percentages
+type PercentFocalOperation struct {
+	X, Y float64
+}
+
+func NewPercentFocalOperation() *PercentFocalOperation {
+	return &PercentFocalOperation{}
+}
+
+func (o *PercentFocalOperation) Name() string {
+	return "pfocal"
+}
+
+func (o *PercentFocalOperation) Aliases() []string {
+	return []string{"pfp"}
+}
+
+func (o *PercentFocalOperation) Clone() Operation {
+	return NewPercentFocalOperation()
+}
+
+func (o *PercentFocalOperation) Parse(filter string) (bool, error) {
+	if !matchesFilter(filter, o.Name(), o.Aliases()) {
+		return false, nil
+	}
+
+	args, err := filterArgs(filter, o.Name())
+	if err != nil {
+		return false, err
+	}
+	if len(args) != 2 {
+		return false, fmt.Errorf("pfocal filter expects 2 coordinates (x,y) as percentages 0-100, got %d", len(args))
+	}
+
+	if o.X, err = parseFloatArg("pfocal x", args[0]); err != nil {
+		return false, err
+	}
+	if o.Y, err = parseFloatArg("pfocal y", args[1]); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Validate checks that both coordinates are percentages
+func (o *PercentFocalOperation) Validate() error {
+	if o.X < 0 || o.X > 100 || o.Y < 0 || o.Y > 100 {
+		return fmt.Errorf("invalid pfocal coordinates: percentages must be between 0 and 100 (got pfocal(%g,%g))", o.X, o.Y)
+	}
+	return nil
+}
+
+func (o *PercentFocalOperation) Apply(img *vips.Image) (*vips.Image, error) {
+	// Focal point is applied by modifying ResizeOperation behavior
+	return img, nil
+}
+
+// FocalPoint returns the focal point for ResizeOperation
+func (o *PercentFocalOperation) FocalPoint() *FocalPoint {
+	return &FocalPoint{X: o.X, Y: o.Y, Percent: true}
+}
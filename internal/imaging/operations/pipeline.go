@@ -1,17 +1,50 @@
 package operations
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"github.com/cshum/vipsgen/vips"
 )
 
-func prepareImage(imageData []byte) (*vips.Image, error) {
-	// Load image, then apply EXIF-based autorotation.
-	// Autorotate cannot be set in load options because not all loaders support it (e.g. WebP).
+// ErrUndecodableImage is returned when the source data cannot be decoded as an image.
+var ErrUndecodableImage = errors.New("unable to decode source image")
+
+// ErrResourceUnavailable is returned when an external resource (e.g. a watermark image) cannot
+// be fetched. The storage error is not wrapped, so it is not mistaken for a missing source.
+var ErrResourceUnavailable = errors.New("resource unavailable")
+
+// loadImage opens the source image. Pixels are decoded lazily, so until an operation needs
+// them only the header is read.
+func loadImage(imageData []byte) (*vips.Image, error) {
 	img, err := vips.NewImageFromBuffer(imageData, vips.DefaultLoadOptions())
 	if err != nil {
-		return nil, fmt.Errorf("failed to load image: %w", err)
+		return nil, fmt.Errorf("failed to load image: %w: %w", ErrUndecodableImage, err)
+	}
+	return img, nil
+}
+
+// prepareImage takes ownership of an image opened by loadImage, reloads it with all frames
+// when requested, then applies EXIF-based autorotation.
+// Autorotate cannot be set in load options because not all loaders support it (e.g. WebP).
+func prepareImage(img *vips.Image, imageData []byte, allPages bool) (*vips.Image, error) {
+	// Reload all frames only for multi-page sources: loaders without pages (JPEG, PNG)
+	// reject the n option.
+	if allPages && img.Pages() > 1 {
+		img.Close()
+		options := vips.DefaultLoadOptions()
+		options.N = -1
+		var err error
+		if img, err = vips.NewImageFromBuffer(imageData, options); err != nil {
+			return nil, fmt.Errorf("failed to load image frames: %w: %w", ErrUndecodableImage, err)
+		}
+	}
+
+	// Frames are stacked vertically, so rotating would mix them up. Animated
+	// formats practically never carry EXIF orientation.
+	if isAnimated(img) {
+		return img, nil
 	}
 
 	if err := img.Autorot(&vips.AutorotOptions{}); err != nil {
@@ -22,21 +55,24 @@ func prepareImage(imageData []byte) (*vips.Image, error) {
 	return img, nil
 }
 
-// ApplyAll applies all operations in the request to the image data
-func ApplyAll(imageData []byte, req *Request) ([]byte, string, error) {
-	img, err := prepareImage(imageData)
+// ApplyAll applies all operations in the request to the image data.
+// The loader provides external resources (e.g. watermark images) to operations that need them.
+func ApplyAll(ctx context.Context, imageData []byte, req *Request, loader ResourceLoader) ([]byte, string, error) {
+	source, err := loadImage(imageData)
+	if err != nil {
+		return nil, "", err
+	}
+	img, animated, err := renderImage(ctx, source, imageData, req.Operations, loader)
 	if err != nil {
 		return nil, "", err
 	}
 	defer img.Close()
 
-	// Extract special operations
+	// Extract export operations
 	var formatOp *FormatOperation
 	var qualityOp *QualityOperation
-	var resizeOp *ResizeOperation
-
-	// Separate resize from other processing operations
-	var processingOps []Operation
+	var metadataOp *MetadataOperation
+	var maxBytesOp *MaxBytesOperation
 
 	for _, op := range req.Operations {
 		switch v := op.(type) {
@@ -44,36 +80,167 @@ func ApplyAll(imageData []byte, req *Request) ([]byte, string, error) {
 			formatOp = v
 		case *QualityOperation:
 			qualityOp = v
+		case *MetadataOperation:
+			metadataOp = v
+		case *MaxBytesOperation:
+			maxBytesOp = v
+		}
+	}
+
+	// Use extracted format and quality for export
+	if formatOp == nil {
+		formatOp = NewFormatOperation()
+	}
+	formatOp.Animated = animated
+	if qualityOp == nil {
+		qualityOp = NewQualityOperation()
+	}
+
+	// Convert colours and select the metadata that survives export
+	if metadataOp != nil {
+		if img, err = metadataOp.Apply(img); err != nil {
+			return nil, "", fmt.Errorf("operation %s failed: %w", metadataOp.Name(), err)
+		}
+		formatOp.Keep = metadataOp.KeepFlags()
+	}
+
+	// With a byte budget, quality(...) becomes the upper bound of the quality search
+	if maxBytesOp != nil {
+		return maxBytesOp.Export(img, formatOp, qualityOp.Quality)
+	}
+	return formatOp.Export(img, qualityOp.Quality)
+}
+
+// renderImage applies all operations that change pixels to the source opened by loadImage,
+// leaving metadata and encoding to the caller. Reports whether the result has several frames.
+// It takes ownership of source; the caller owns the returned image.
+func renderImage(ctx context.Context, source *vips.Image, imageData []byte, ops []Operation, loader ResourceLoader) (*vips.Image, bool, error) {
+	// Fetch external resources before decoding pixels, so missing ones fail fast
+	if err := loadResources(ctx, ops, loader); err != nil {
+		source.Close()
+		return nil, false, err
+	}
+
+	// Extract special operations
+	var resizeOp *ResizeOperation
+	var trimOp *TrimOperation
+	var animatedOp *AnimatedOperation
+
+	// Separate resize from other processing operations
+	var orientationOps []Operation
+	var processingOps []Operation
+	var postResizeOps []Operation
+	var overlayOps []Operation
+
+	for _, op := range ops {
+		switch v := op.(type) {
+		case *FormatOperation, *QualityOperation, *MetadataOperation, *MaxBytesOperation:
+			// Applied during export
 		case *ResizeOperation:
 			resizeOp = v
+		case *TrimOperation:
+			trimOp = v
+		case *AnimatedOperation:
+			animatedOp = v
 		default:
-			processingOps = append(processingOps, op)
+			switch stageOf(op) {
+			case StageOrientation:
+				orientationOps = append(orientationOps, op)
+			case StagePostResize:
+				postResizeOps = append(postResizeOps, op)
+			case StageOverlay:
+				overlayOps = append(overlayOps, op)
+			default:
+				processingOps = append(processingOps, op)
+			}
 		}
 	}
 
-	// Apply processing operations first (crop, fit, etc.)
-	for _, op := range processingOps {
-		img, err = op.Apply(img)
-		if err != nil {
-			return nil, "", fmt.Errorf("operation %s failed: %w", op.Name(), err)
+	// Trim runs first, regardless of its position in the filter list, so crop coordinates
+	// refer to the trimmed image.
+	if trimOp != nil {
+		processingOps = append([]Operation{trimOp}, processingOps...)
+	}
+
+	process := func(img *vips.Image) error {
+		// Apply explicit orientation first (rotate, flip), so crop coordinates refer to the result
+		if _, err := applyOperations(img, orientationOps); err != nil {
+			return err
+		}
+
+		// Apply processing operations (trim, crop, fit, etc.)
+		if _, err := applyOperations(img, processingOps); err != nil {
+			return err
 		}
+
+		// Apply resize after processing to ensure output dimensions match request
+		if resizeOp != nil {
+			if _, err := resizeOp.Apply(img); err != nil {
+				return fmt.Errorf("operation %s failed: %w", resizeOp.Name(), err)
+			}
+		}
+
+		// Apply effects that must work on the output-sized image (blur, sharpen, etc.)
+		if _, err := applyOperations(img, postResizeOps); err != nil {
+			return err
+		}
+
+		// Composite overlays (watermark, etc.) last, so they are not affected by effects
+		_, err := applyOperations(img, overlayOps)
+		return err
 	}
 
-	// Apply resize LAST to ensure output dimensions match request
-	if resizeOp != nil {
-		img, err = resizeOp.Apply(img)
-		if err != nil {
-			return nil, "", fmt.Errorf("operation %s failed: %w", resizeOp.Name(), err)
+	img, err := prepareImage(source, imageData, animatedOp != nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !isAnimated(img) {
+		if err := process(img); err != nil {
+			img.Close()
+			return nil, false, err
 		}
+		return img, false, nil
 	}
 
-	// Use extracted format and quality for export
-	if formatOp == nil {
-		formatOp = NewFormatOperation()
+	defer img.Close()
+	if err := animatedOp.CheckLimits(img); err != nil {
+		return nil, false, err
 	}
-	if qualityOp == nil {
-		qualityOp = NewQualityOperation()
+
+	// Process frames separately, so crop and resize apply to each frame
+	frames, err := applyPerFrame(img, process)
+	if err != nil {
+		return nil, false, err
 	}
+	return frames, true, nil
+}
 
-	return formatOp.Export(img, qualityOp.Quality)
+// loadResources lets operations fetch the external resources they reference
+func loadResources(ctx context.Context, ops []Operation, loader ResourceLoader) error {
+	for _, op := range ops {
+		user, ok := op.(ResourceUser)
+		if !ok {
+			continue
+		}
+		if loader == nil {
+			return fmt.Errorf("operation %s requires a resource loader", op.Name())
+		}
+		if err := user.LoadResources(ctx, loader); err != nil {
+			return fmt.Errorf("operation %s failed: %w: %v", op.Name(), ErrResourceUnavailable, err)
+		}
+	}
+	return nil
+}
+
+// applyOperations applies operations to the image in order
+func applyOperations(img *vips.Image, ops []Operation) (*vips.Image, error) {
+	var err error
+	for _, op := range ops {
+		img, err = op.Apply(img)
+		if err != nil {
+			return nil, fmt.Errorf("operation %s failed: %w", op.Name(), err)
+		}
+	}
+	return img, nil
 }
@@ -0,0 +1,89 @@
+package operations
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// RadiusOperation handles radius(px) and radius(percent%) filters.
+// Corners outside the radius become transparent.
+type RadiusOperation struct {
+	Radius  float64
+	Percent bool   // Radius is a percentage of the shorter side
+	Format  string // Set during validation to check transparent compatibility
+}
+
+func NewRadiusOperation() *RadiusOperation {
+	return &RadiusOperation{}
+}
+
+func (o *RadiusOperation) Name() string {
+	return "radius"
+}
+
+func (o *RadiusOperation) Aliases() []string {
+	return []string{}
+}
+
+func (o *RadiusOperation) Clone() Operation {
+	return NewRadiusOperation()
+}
+
+// Stage rounds corners after resize, so the radius is in output pixels
+func (o *RadiusOperation) Stage() Stage {
+	return StagePostResize
+}
+
+func (o *RadiusOperation) Parse(filter string) (bool, error) {
+	if !matchesFilter(filter, o.Name(), o.Aliases()) {
+		return false, nil
+	}
+
+	args, err := filterArgs(filter, o.Name())
+	if err != nil {
+		return false, err
+	}
+	if len(args) != 1 {
+		return false, fmt.Errorf("radius filter expects 1 parameter (px or percent%%), got %d", len(args))
+	}
+
+	value, percent := strings.CutSuffix(args[0], "%")
+	radius, err := parseFloatArg("radius", value)
+	if err != nil {
+		return false, err
+	}
+
+	o.Radius = radius
+	o.Percent = percent
+	return true, nil
+}
+
+// Shape returns the mask outline, followed by border()
+func (o *RadiusOperation) Shape() MaskShape {
+	return MaskShape{Radius: o.Radius, Percent: o.Percent}
+}
+
+// SetOutputFormat records the output format for transparency validation
+func (o *RadiusOperation) SetOutputFormat(format string) {
+	o.Format = format
+}
+
+// Validate checks the radius range and that the output format can carry the transparent corners
+func (o *RadiusOperation) Validate() error {
+	if o.Percent && (o.Radius <= 0 || o.Radius > 50) {
+		return fmt.Errorf("radius percentage must be greater than 0 and at most 50, got: %g%%", o.Radius)
+	}
+	if !o.Percent && o.Radius <= 0 {
+		return fmt.Errorf("radius must be greater than 0, got: %g", o.Radius)
+	}
+	return checkAlphaFormat("radius", o.Format, 0)
+}
+
+func (o *RadiusOperation) Apply(img *vips.Image) (*vips.Image, error) {
+	if err := applyMask(img, o.Shape()); err != nil {
+		return nil, err
+	}
+	return img, nil
+}
@@ -11,7 +11,7 @@ type Registry struct {
 }
 
 // NewRegistry creates a new operation registry with all operations registered
-func NewRegistry(maxWidth, maxHeight, maxResolution int) *Registry {
+func NewRegistry(maxWidth, maxHeight, maxResolution, maxFrames, maxAnimationResolution int) *Registry {
 	r := &Registry{
 		resizeOp:  NewResizeOperation(maxWidth, maxHeight, maxResolution),
 		formatOp:  NewFormatOperation(),
@@ -23,8 +23,37 @@ func NewRegistry(maxWidth, maxHeight, maxResolution int) *Registry {
 		r.formatOp,
 		r.qualityOp,
 		NewFitOperation(),
+		NewGravityOperation(),
+		NewFocalOperation(),
+		NewPercentFocalOperation(),
 		NewCropOperation(),
 		NewPercentCropOperation(),
+		NewRotateOperation(),
+		NewFlipOperation(),
+		NewBlurOperation(),
+		NewSharpenOperation(),
+		NewDPROperation(),
+		NewWatermarkOperation(),
+		NewTextOperation(),
+		NewGrayscaleOperation(),
+		NewBrightnessOperation(),
+		NewContrastOperation(),
+		NewSaturationOperation(),
+		NewGammaOperation(),
+		NewTintOperation(),
+		NewRadiusOperation(),
+		NewCircleOperation(),
+		NewBorderOperation(),
+		NewTrimOperation(),
+		NewMetadataOperation(),
+		NewAnimatedOperation(maxFrames, maxAnimationResolution),
+		NewProgressiveOperation(),
+		NewOptimizeOperation(),
+		NewSubsamplingOperation(),
+		NewEffortOperation(),
+		NewLosslessOperation(),
+		NewPaletteOperation(),
+		NewMaxBytesOperation(),
 	}
 
 	return r
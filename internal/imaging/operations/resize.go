@@ -11,8 +11,11 @@ import (
 type ResizeOperation struct {
 	Width     *int
 	Height    *int
-	Fit       string // "cover" or "fill"
-	FillColor string // Color for fill mode (default "white")
+	Fit       string      // One of the Fit* modes (default cover)
+	FillColor Color       // Color for fill mode (default white)
+	NoUpscale bool        // Never enlarge the source beyond its original size
+	Gravity   string      // Crop gravity for cover mode (default "centre")
+	Focal     *FocalPoint // Optional focal point for cover mode, exclusive with Gravity
 
 	maxWidth      int
 	maxHeight     int
@@ -21,8 +24,8 @@ type ResizeOperation struct {
 
 func NewResizeOperation(maxWidth, maxHeight, maxResolution int) *ResizeOperation {
 	return &ResizeOperation{
-		Fit:           "cover",
-		FillColor:     "white",
+		Fit:           FitCover,
+		FillColor:     ColorWhite,
 		maxWidth:      maxWidth,
 		maxHeight:     maxHeight,
 		maxResolution: maxResolution,
@@ -94,6 +97,14 @@ func (o *ResizeOperation) Validate() error {
 		return fmt.Errorf("invalid height: %d exceeds maximum allowed value %d", *o.Height, o.maxHeight)
 	}
 
+	if o.Gravity != "" && o.Gravity != GravityCentre && o.Fit != FitCover {
+		return fmt.Errorf("gravity(%s) is only supported with fit(cover)", o.Gravity)
+	}
+
+	if o.Focal != nil && o.Fit != FitCover {
+		return fmt.Errorf("focal point is only supported with fit(cover)")
+	}
+
 	if o.Width != nil && o.Height != nil {
 		if area := *o.Width * *o.Height; area > o.maxResolution {
 			return fmt.Errorf("invalid dimensions: %dx%d (%d px) exceeds maximum resolution %d px", *o.Width, *o.Height, area, o.maxResolution)
@@ -128,7 +139,7 @@ func (o *ResizeOperation) resizeHeightOnly(img *vips.Image, height int) (*vips.I
 	if currentHeight <= 0 {
 		return nil, fmt.Errorf("failed to resize by height: invalid source height %d", currentHeight)
 	}
-	if height == currentHeight {
+	if height == currentHeight || (o.NoUpscale && height > currentHeight) {
 		return img, nil
 	}
 
@@ -146,7 +157,7 @@ func (o *ResizeOperation) resizeWidthOnly(img *vips.Image, width int) (*vips.Ima
 	if currentWidth <= 0 {
 		return nil, fmt.Errorf("failed to resize by width: invalid source width %d", currentWidth)
 	}
-	if width == currentWidth {
+	if width == currentWidth || (o.NoUpscale && width > currentWidth) {
 		return img, nil
 	}
 
@@ -160,13 +171,33 @@ func (o *ResizeOperation) resizeWidthOnly(img *vips.Image, width int) (*vips.Ima
 }
 
 func (o *ResizeOperation) resizeBoth(img *vips.Image, width, height int) (*vips.Image, error) {
-	if o.Fit == "fill" {
+	switch o.Fit {
+	case FitFill:
 		return o.resizeFill(img, width, height)
+	case FitInside:
+		return o.resizeInside(img, width, height)
+	case FitOutside:
+		return o.resizeOutside(img, width, height)
+	case FitStretch:
+		return o.resizeStretch(img, width, height)
+	}
+
+	// Default to cover mode. Without upscaling, a source smaller than the box is only
+	// cropped, so the output shrinks to the source size along that axis.
+	if o.NoUpscale {
+		width = min(width, img.Width())
+		height = min(height, img.Height())
 	}
-	// Default to cover mode
+	if o.Focal != nil {
+		return o.resizeCoverFocal(img, width, height)
+	}
+	if !isSmartGravity(o.Gravity) {
+		return o.resizeCoverGravity(img, width, height)
+	}
+
 	err := img.ThumbnailImage(width, &vips.ThumbnailImageOptions{
 		Height: height,
-		Crop:   vips.InterestingCentre,
+		Crop:   interestingFor(o.Gravity),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to resize (cover): %w", err)
@@ -174,6 +205,143 @@ func (o *ResizeOperation) resizeBoth(img *vips.Image, width, height int) (*vips.
 	return img, nil
 }
 
+// resizeCoverGravity scales the image to cover the target box, then crops the excess
+// according to a compass gravity (north, south-east, ...).
+func (o *ResizeOperation) resizeCoverGravity(img *vips.Image, width, height int) (*vips.Image, error) {
+	if err := resizeToCover(img, width, height); err != nil {
+		return nil, err
+	}
+
+	left, top := gravityOffset(o.Gravity, img.Width()-width, img.Height()-height)
+	if err := img.ExtractArea(left, top, width, height); err != nil {
+		return nil, fmt.Errorf("failed to crop (cover, gravity %s): %w", o.Gravity, err)
+	}
+	return img, nil
+}
+
+// resizeCoverFocal scales the image to cover the target box, then crops it so that the
+// focal point ends up as close to the centre as the image edges allow.
+func (o *ResizeOperation) resizeCoverFocal(img *vips.Image, width, height int) (*vips.Image, error) {
+	sourceWidth, sourceHeight := img.Width(), img.Height()
+
+	// Points outside the image (e.g. after trim) end up at the edge by the crop clamping below
+	focalX, focalY := o.Focal.X, o.Focal.Y
+	if o.Focal.Percent {
+		focalX = focalX * float64(sourceWidth) / 100
+		focalY = focalY * float64(sourceHeight) / 100
+	}
+
+	if err := resizeToCover(img, width, height); err != nil {
+		return nil, err
+	}
+
+	scaleX := float64(img.Width()) / float64(sourceWidth)
+	scaleY := float64(img.Height()) / float64(sourceHeight)
+
+	left := clampInt(int(focalX*scaleX-float64(width)/2+0.5), 0, img.Width()-width)
+	top := clampInt(int(focalY*scaleY-float64(height)/2+0.5), 0, img.Height()-height)
+
+	if err := img.ExtractArea(left, top, width, height); err != nil {
+		return nil, fmt.Errorf("failed to crop (cover, focal point): %w", err)
+	}
+	return img, nil
+}
+
+func clampInt(v, lo, hi int) int {
+	return max(lo, min(v, hi))
+}
+
+// resizeToCover scales the image so that it covers width x height while keeping the
+// aspect ratio. One dimension matches the box exactly, the other may exceed it.
+func resizeToCover(img *vips.Image, width, height int) error {
+	currentWidth, currentHeight := img.Width(), img.Height()
+	if currentWidth <= 0 || currentHeight <= 0 {
+		return fmt.Errorf("failed to resize (cover): invalid source size %dx%d", currentWidth, currentHeight)
+	}
+
+	scale := max(float64(width)/float64(currentWidth), float64(height)/float64(currentHeight))
+	options := vips.DefaultResizeOptions()
+	options.Vscale = scale
+	if err := img.Resize(scale, options); err != nil {
+		return fmt.Errorf("failed to resize (cover): %w", err)
+	}
+
+	// Guard against rounding leaving the image a pixel short of the box
+	if img.Width() < width || img.Height() < height {
+		if err := img.Embed(0, 0, max(width, img.Width()), max(height, img.Height()), &vips.EmbedOptions{
+			Extend: vips.ExtendCopy,
+		}); err != nil {
+			return fmt.Errorf("failed to resize (cover): %w", err)
+		}
+	}
+	return nil
+}
+
+// resizeInside scales the image to fit within the box while keeping the aspect ratio.
+// Unlike fill, no padding is added, so one dimension may be smaller than requested.
+func (o *ResizeOperation) resizeInside(img *vips.Image, width, height int) (*vips.Image, error) {
+	size := vips.SizeBoth
+	if o.NoUpscale {
+		size = vips.SizeDown
+	}
+
+	err := img.ThumbnailImage(width, &vips.ThumbnailImageOptions{
+		Height: height,
+		Size:   size,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to resize (inside): %w", err)
+	}
+	return img, nil
+}
+
+// resizeOutside scales the image to cover the box while keeping the aspect ratio.
+// Unlike cover, nothing is cropped, so one dimension may be larger than requested.
+func (o *ResizeOperation) resizeOutside(img *vips.Image, width, height int) (*vips.Image, error) {
+	currentWidth, currentHeight := img.Width(), img.Height()
+	if currentWidth <= 0 || currentHeight <= 0 {
+		return nil, fmt.Errorf("failed to resize (outside): invalid source size %dx%d", currentWidth, currentHeight)
+	}
+
+	scale := max(float64(width)/float64(currentWidth), float64(height)/float64(currentHeight))
+	if o.NoUpscale && scale > 1 {
+		return img, nil
+	}
+
+	options := vips.DefaultResizeOptions()
+	options.Vscale = scale
+	if err := img.Resize(scale, options); err != nil {
+		return nil, fmt.Errorf("failed to resize (outside): %w", err)
+	}
+	return img, nil
+}
+
+// resizeStretch scales each axis independently to the exact box, ignoring the aspect ratio.
+// Without upscaling, an axis smaller than the box keeps its original size.
+func (o *ResizeOperation) resizeStretch(img *vips.Image, width, height int) (*vips.Image, error) {
+	currentWidth, currentHeight := img.Width(), img.Height()
+	if currentWidth <= 0 || currentHeight <= 0 {
+		return nil, fmt.Errorf("failed to resize (stretch): invalid source size %dx%d", currentWidth, currentHeight)
+	}
+
+	hscale := float64(width) / float64(currentWidth)
+	vscale := float64(height) / float64(currentHeight)
+	if o.NoUpscale {
+		hscale = min(hscale, 1)
+		vscale = min(vscale, 1)
+	}
+	if hscale == 1 && vscale == 1 {
+		return img, nil
+	}
+
+	options := vips.DefaultResizeOptions()
+	options.Vscale = vscale
+	if err := img.Resize(hscale, options); err != nil {
+		return nil, fmt.Errorf("failed to resize (stretch): %w", err)
+	}
+	return img, nil
+}
+
 func (o *ResizeOperation) resizeFill(img *vips.Image, targetWidth, targetHeight int) (*vips.Image, error) {
 	// Resize to fit within target dimensions (maintaining aspect ratio)
 	err := img.ThumbnailImage(targetWidth, &vips.ThumbnailImageOptions{
@@ -197,52 +365,10 @@ func (o *ResizeOperation) resizeFill(img *vips.Image, targetWidth, targetHeight
 	left := (targetWidth - newWidth) / 2
 	top := (targetHeight - newHeight) / 2
 
-	// Handle transparent mode - works with PNG and WebP formats
-	if o.FillColor == "transparent" {
-		// Use transparent background (RGBA with alpha channel)
-		err = img.Embed(left, top, targetWidth, targetHeight, &vips.EmbedOptions{
-			Extend:     vips.ExtendBackground,
-			Background: []float64{0, 0, 0, 0},
-		})
-		if err != nil {
-			return nil, fmt.Errorf("failed to embed image with transparent background: %w", err)
-		}
-		return img, nil
-	}
-
-	// Determine background color for non-transparent fills.
-	bands := img.Bands()
-	hasAlpha := img.HasAlpha()
-	var bgColor []float64
-
-	if hasAlpha {
-		if bands == 2 {
-			if o.FillColor == "black" {
-				bgColor = []float64{0, 255}
-			} else {
-				bgColor = []float64{255, 255}
-			}
-		} else {
-			if o.FillColor == "black" {
-				bgColor = []float64{0, 0, 0, 255}
-			} else {
-				bgColor = []float64{255, 255, 255, 255}
-			}
-		}
-	} else {
-		if bands == 1 {
-			if o.FillColor == "black" {
-				bgColor = []float64{0}
-			} else {
-				bgColor = []float64{255}
-			}
-		} else {
-			if o.FillColor == "black" {
-				bgColor = []float64{0, 0, 0}
-			} else {
-				bgColor = []float64{255, 255, 255}
-			}
-		}
+	// Transparent fills add an alpha channel - works with PNG, WebP and AVIF formats
+	bgColor, err := o.FillColor.Background(img)
+	if err != nil {
+		return nil, err
 	}
 
 	// Embed the image in a canvas with the target dimensions
@@ -0,0 +1,112 @@
+package operations
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// RotateOperation handles rotate(angle[,bg]) filter.
+// Angles are in degrees clockwise. Multiples of 90 are lossless; other angles enlarge the
+// canvas and fill the corners with the background colour.
+type RotateOperation struct {
+	Angle      float64
+	Background Color  // Corner fill for non-right angles (default black)
+	Format     string // Set during validation to check transparent compatibility
+}
+
+func NewRotateOperation() *RotateOperation {
+	return &RotateOperation{
+		Background: ColorBlack,
+	}
+}
+
+func (o *RotateOperation) Name() string {
+	return "rotate"
+}
+
+func (o *RotateOperation) Aliases() []string {
+	return []string{"rot"}
+}
+
+func (o *RotateOperation) Clone() Operation {
+	return NewRotateOperation()
+}
+
+// Stage runs rotate before crop, so crop coordinates refer to the rotated image
+func (o *RotateOperation) Stage() Stage {
+	return StageOrientation
+}
+
+func (o *RotateOperation) Parse(filter string) (bool, error) {
+	if !matchesFilter(filter, o.Name(), o.Aliases()) {
+		return false, nil
+	}
+
+	args, err := filterArgs(filter, o.Name())
+	if err != nil {
+		return false, err
+	}
+	if len(args) != 1 && len(args) != 2 {
+		return false, fmt.Errorf("rotate filter expects 1 or 2 parameters (angle[,bg]), got %d", len(args))
+	}
+
+	if o.Angle, err = parseFloatArg("rotate angle", args[0]); err != nil {
+		return false, err
+	}
+
+	if len(args) == 2 {
+		if o.Background, err = ParseColor(args[1]); err != nil {
+			return false, fmt.Errorf("invalid rotate background: %w", err)
+		}
+	}
+
+	return true, nil
+}
+
+// SetOutputFormat records the output format for transparency validation
+func (o *RotateOperation) SetOutputFormat(format string) {
+	o.Format = format
+}
+
+// Validate checks the angle range and that a transparent background is used with an alpha-capable format
+func (o *RotateOperation) Validate() error {
+	if o.Angle < -360 || o.Angle > 360 {
+		return fmt.Errorf("rotate angle must be between -360 and 360, got: %g", o.Angle)
+	}
+	if !o.Background.IsOpaque() {
+		return checkAlphaFormat("transparent rotate background", o.Format, o.Background.A)
+	}
+	return nil
+}
+
+func (o *RotateOperation) Apply(img *vips.Image) (*vips.Image, error) {
+	angle := math.Mod(o.Angle, 360)
+	if angle < 0 {
+		angle += 360
+	}
+
+	var err error
+	switch angle {
+	case 0:
+		return img, nil
+	case 90:
+		err = img.Rot(vips.AngleD90)
+	case 180:
+		err = img.Rot(vips.AngleD180)
+	case 270:
+		err = img.Rot(vips.AngleD270)
+	default:
+		var bg []float64
+		if bg, err = o.Background.Background(img); err != nil {
+			return nil, err
+		}
+		err = img.Rotate(angle, &vips.RotateOptions{Background: bg})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate image: %w", err)
+	}
+
+	return img, nil
+}
@@ -0,0 +1,84 @@
+package operations
+
+import (
+	"fmt"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// SaturationOperation handles saturation(-100..100) filter.
+// The value is a percentage change in chroma: -100 removes all colour, 100 doubles it.
+type SaturationOperation struct {
+	Saturation float64
+}
+
+func NewSaturationOperation() *SaturationOperation {
+	return &SaturationOperation{}
+}
+
+func (o *SaturationOperation) Name() string {
+	return "saturation"
+}
+
+func (o *SaturationOperation) Aliases() []string {
+	return []string{"sat"}
+}
+
+func (o *SaturationOperation) Clone() Operation {
+	return NewSaturationOperation()
+}
+
+func (o *SaturationOperation) Stage() Stage {
+	return StagePostResize
+}
+
+func (o *SaturationOperation) Parse(filter string) (bool, error) {
+	if !matchesFilter(filter, o.Name(), o.Aliases()) {
+		return false, nil
+	}
+
+	value, err := parseToneArg(filter, o.Name())
+	if err != nil {
+		return false, err
+	}
+
+	o.Saturation = value
+	return true, nil
+}
+
+// Validate checks that saturation is within -100..100
+func (o *SaturationOperation) Validate() error {
+	return validateToneArg("saturation", o.Saturation)
+}
+
+func (o *SaturationOperation) Apply(img *vips.Image) (*vips.Image, error) {
+	// Grayscale images have no chroma to adjust
+	if colourBands(img) < 3 {
+		return img, nil
+	}
+
+	interpretation := img.Interpretation()
+	format := img.BandFormat()
+
+	// Scale chroma in LCh, keeping lightness, hue and alpha
+	if err := img.Colourspace(vips.InterpretationLch, nil); err != nil {
+		return nil, fmt.Errorf("failed to adjust saturation: %w", err)
+	}
+
+	multiply := make([]float64, img.Bands())
+	for i := range multiply {
+		multiply[i] = 1
+	}
+	multiply[1] = 1 + o.Saturation/100
+	if err := img.Linear(multiply, make([]float64, img.Bands()), nil); err != nil {
+		return nil, fmt.Errorf("failed to adjust saturation: %w", err)
+	}
+
+	if err := img.Colourspace(interpretation, nil); err != nil {
+		return nil, fmt.Errorf("failed to adjust saturation: %w", err)
+	}
+	if err := img.Cast(format, nil); err != nil {
+		return nil, fmt.Errorf("failed to adjust saturation: %w", err)
+	}
+	return img, nil
+}
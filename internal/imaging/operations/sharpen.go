@@ -0,0 +1,101 @@
+package operations
+
+import (
+	"fmt"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// Sharpen parameter bounds, wide enough for any visible effect on thumbnails
+const (
+	minSharpenSigma = 0.1
+	maxSharpenSigma = 10
+	maxSharpenX1    = 10
+	maxSharpenM2    = 50
+)
+
+// SharpenOperation handles sharpen(sigma[,x1,m2]) filter
+type SharpenOperation struct {
+	Sigma float64
+	X1    float64 // Flat/jaggy threshold
+	M2    float64 // Slope for jaggy areas
+}
+
+func NewSharpenOperation() *SharpenOperation {
+	return &SharpenOperation{
+		X1: 2, // libvips defaults
+		M2: 3,
+	}
+}
+
+func (o *SharpenOperation) Name() string {
+	return "sharpen"
+}
+
+func (o *SharpenOperation) Aliases() []string {
+	return []string{}
+}
+
+func (o *SharpenOperation) Clone() Operation {
+	return NewSharpenOperation()
+}
+
+// Stage runs sharpen after resize to restore detail lost when downscaling
+func (o *SharpenOperation) Stage() Stage {
+	return StagePostResize
+}
+
+func (o *SharpenOperation) Parse(filter string) (bool, error) {
+	if !matchesFilter(filter, o.Name(), o.Aliases()) {
+		return false, nil
+	}
+
+	args, err := filterArgs(filter, o.Name())
+	if err != nil {
+		return false, err
+	}
+	if len(args) != 1 && len(args) != 3 {
+		return false, fmt.Errorf("sharpen filter expects 1 or 3 parameters (sigma[,x1,m2]), got %d", len(args))
+	}
+
+	if o.Sigma, err = parseFloatArg("sharpen sigma", args[0]); err != nil {
+		return false, err
+	}
+
+	if len(args) == 3 {
+		if o.X1, err = parseFloatArg("sharpen x1", args[1]); err != nil {
+			return false, err
+		}
+		if o.M2, err = parseFloatArg("sharpen m2", args[2]); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// Validate checks that all parameters are within the supported ranges
+func (o *SharpenOperation) Validate() error {
+	if o.Sigma < minSharpenSigma || o.Sigma > maxSharpenSigma {
+		return fmt.Errorf("sharpen sigma must be between %g and %g, got: %g", float64(minSharpenSigma), float64(maxSharpenSigma), o.Sigma)
+	}
+	if o.X1 < 0 || o.X1 > maxSharpenX1 {
+		return fmt.Errorf("sharpen x1 must be between 0 and %g, got: %g", float64(maxSharpenX1), o.X1)
+	}
+	if o.M2 < 0 || o.M2 > maxSharpenM2 {
+		return fmt.Errorf("sharpen m2 must be between 0 and %g, got: %g", float64(maxSharpenM2), o.M2)
+	}
+	return nil
+}
+
+func (o *SharpenOperation) Apply(img *vips.Image) (*vips.Image, error) {
+	options := vips.DefaultSharpenOptions()
+	options.Sigma = o.Sigma
+	options.X1 = o.X1
+	options.M2 = o.M2
+
+	if err := img.Sharpen(options); err != nil {
+		return nil, fmt.Errorf("failed to sharpen image: %w", err)
+	}
+	return img, nil
+}
@@ -0,0 +1,65 @@
+package operations
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// SubsamplingOperation handles subsampling(auto|444|420) filter.
+// It selects chroma subsampling for JPEG, AVIF and HEIC; 444 keeps sharp colored edges,
+// such as text and logos, at the cost of larger files.
+type SubsamplingOperation struct {
+	Subsampling string
+}
+
+func NewSubsamplingOperation() *SubsamplingOperation {
+	return &SubsamplingOperation{
+		Subsampling: SubsamplingAuto,
+	}
+}
+
+func (o *SubsamplingOperation) Name() string {
+	return "subsampling"
+}
+
+func (o *SubsamplingOperation) Aliases() []string {
+	return []string{"ss"}
+}
+
+func (o *SubsamplingOperation) Clone() Operation {
+	return NewSubsamplingOperation()
+}
+
+func (o *SubsamplingOperation) Parse(filter string) (bool, error) {
+	if !matchesFilter(filter, o.Name(), o.Aliases()) {
+		return false, nil
+	}
+
+	args, err := filterArgs(filter, o.Name())
+	if err != nil {
+		return false, err
+	}
+	if len(args) != 1 {
+		return false, fmt.Errorf("subsampling filter expects 1 parameter (auto, 444 or 420), got %d", len(args))
+	}
+
+	o.Subsampling = strings.ToLower(args[0])
+	return true, nil
+}
+
+// Validate checks the subsampling mode
+func (o *SubsamplingOperation) Validate() error {
+	return EncodeOptions{Subsampling: o.Subsampling}.Validate()
+}
+
+// ApplyEncoding overrides the server default
+func (o *SubsamplingOperation) ApplyEncoding(options *EncodeOptions) {
+	options.Subsampling = o.Subsampling
+}
+
+func (o *SubsamplingOperation) Apply(img *vips.Image) (*vips.Image, error) {
+	// Subsampling is applied during export
+	return img, nil
+}
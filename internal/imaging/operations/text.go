@@ -0,0 +1,218 @@
+package operations
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// Text overlay bounds
+const (
+	minTextSize   = 6
+	maxTextSize   = 200
+	maxTextLength = 500
+)
+
+// TextOperation handles text(content[,position[,size[,color[,background[,font]]]]]) filter.
+// The caption is rendered with libvips/Pango and composited onto the output image.
+type TextOperation struct {
+	Text       string
+	Position   string // Compass gravity (default "south")
+	Size       int    // Font size in output pixels (default 24)
+	Color      Color  // Text color (default white)
+	Background Color  // Box behind the text (default transparent, no box)
+	Font       string // Font family and style, e.g. "sans bold" (default "sans")
+}
+
+func NewTextOperation() *TextOperation {
+	return &TextOperation{
+		Position:   "south",
+		Size:       24,
+		Color:      ColorWhite,
+		Background: ColorTransparent,
+		Font:       "sans",
+	}
+}
+
+func (o *TextOperation) Name() string {
+	return "text"
+}
+
+func (o *TextOperation) Aliases() []string {
+	return []string{}
+}
+
+func (o *TextOperation) Clone() Operation {
+	return NewTextOperation()
+}
+
+// Stage renders text last, so the font size refers to the output image
+func (o *TextOperation) Stage() Stage {
+	return StageOverlay
+}
+
+func (o *TextOperation) Parse(filter string) (bool, error) {
+	if !matchesFilter(filter, o.Name(), o.Aliases()) {
+		return false, nil
+	}
+
+	args, err := filterArgs(filter, o.Name())
+	if err != nil {
+		return false, err
+	}
+	if len(args) == 0 || len(args) > 6 {
+		return false, fmt.Errorf("text filter expects 1 to 6 parameters (content,position,size,color,background,font), got %d", len(args))
+	}
+
+	if o.Text, err = decodeTextArg("text", args[0]); err != nil {
+		return false, err
+	}
+
+	if len(args) > 1 && args[1] != "" {
+		position, ok := gravityAliases[strings.ToLower(args[1])]
+		if !ok || position == GravityAttention || position == GravityEntropy {
+			return false, fmt.Errorf("invalid text position: %s", args[1])
+		}
+		o.Position = position
+	}
+
+	if len(args) > 2 && args[2] != "" {
+		if o.Size, err = parsePositiveInt(args[2]); err != nil {
+			return false, fmt.Errorf("invalid text size: %w", err)
+		}
+	}
+
+	if len(args) > 3 && args[3] != "" {
+		if o.Color, err = ParseColor(args[3]); err != nil {
+			return false, fmt.Errorf("invalid text color: %w", err)
+		}
+	}
+
+	if len(args) > 4 && args[4] != "" {
+		if o.Background, err = ParseColor(args[4]); err != nil {
+			return false, fmt.Errorf("invalid text background: %w", err)
+		}
+	}
+
+	if len(args) > 5 && args[5] != "" {
+		if o.Font, err = decodeTextArg("text font", args[5]); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// Validate checks the caption, size and font
+func (o *TextOperation) Validate() error {
+	if strings.TrimSpace(o.Text) == "" {
+		return fmt.Errorf("text content is empty")
+	}
+	if !utf8.ValidString(o.Text) {
+		return fmt.Errorf("text content must be valid UTF-8")
+	}
+	if length := utf8.RuneCountInString(o.Text); length > maxTextLength {
+		return fmt.Errorf("text content must be at most %d characters, got: %d", maxTextLength, length)
+	}
+	if o.Size < minTextSize || o.Size > maxTextSize {
+		return fmt.Errorf("text size must be between %d and %d, got: %d", minTextSize, maxTextSize, o.Size)
+	}
+	if !isValidFontName(o.Font) {
+		return fmt.Errorf("invalid text font %q: only letters, digits, spaces and '-' are allowed", o.Font)
+	}
+	return nil
+}
+
+func isValidFontName(font string) bool {
+	if strings.TrimSpace(font) == "" {
+		return false
+	}
+	for _, c := range font {
+		if !((c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == ' ' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+func (o *TextOperation) Apply(img *vips.Image) (*vips.Image, error) {
+	padding := max(o.Size/2, 1)
+
+	overlay, err := vips.NewText(o.markup(), &vips.TextOptions{
+		Font:  fmt.Sprintf("%s %d", o.Font, o.Size),
+		Width: max(img.Width()-2*padding, 1),
+		Align: textAlign(o.Position),
+		Dpi:   72, // 1pt = 1px, so Size is in output pixels
+		Rgba:  true,
+		Wrap:  vips.TextWrapWord,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render text: %w", err)
+	}
+	defer overlay.Close()
+
+	// Pad the caption, so it does not touch the image edges or the box border
+	if err := overlay.Embed(padding, padding, overlay.Width()+2*padding, overlay.Height()+2*padding, &vips.EmbedOptions{
+		Extend:     vips.ExtendBackground,
+		Background: []float64{0, 0, 0, 0},
+	}); err != nil {
+		return nil, fmt.Errorf("failed to pad text: %w", err)
+	}
+
+	if o.Background.A > 0 {
+		box, err := o.drawBox(overlay)
+		if err != nil {
+			return nil, fmt.Errorf("failed to draw text background: %w", err)
+		}
+		defer box.Close()
+		overlay = box
+	}
+
+	if err := compositeOverlay(img, overlay, o.Position); err != nil {
+		return nil, fmt.Errorf("failed to composite text: %w", err)
+	}
+	return img, nil
+}
+
+// markup wraps the escaped caption in Pango markup carrying the text color
+func (o *TextOperation) markup() string {
+	attrs := fmt.Sprintf(`foreground="#%02x%02x%02x"`, o.Color.R, o.Color.G, o.Color.B)
+	if !o.Color.IsOpaque() {
+		attrs += fmt.Sprintf(` fgalpha="%d%%"`, max(int(o.Color.A)*100/255, 1))
+	}
+	return fmt.Sprintf("<span %s>%s</span>", attrs, html.EscapeString(o.Text))
+}
+
+// drawBox returns the padded caption placed on a solid box of the background color
+func (o *TextOperation) drawBox(caption *vips.Image) (*vips.Image, error) {
+	box, err := caption.Copy(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	bg := o.Background
+	if err := box.Linear([]float64{0, 0, 0, 0}, []float64{float64(bg.R), float64(bg.G), float64(bg.B), float64(bg.A)}, &vips.LinearOptions{Uchar: true}); err != nil {
+		box.Close()
+		return nil, err
+	}
+	if err := box.Composite2(caption, vips.BlendModeOver, vips.DefaultComposite2Options()); err != nil {
+		box.Close()
+		return nil, err
+	}
+	return box, nil
+}
+
+// textAlign aligns multi-line captions towards the side they are placed on
+func textAlign(position string) vips.Align {
+	switch {
+	case strings.HasSuffix(position, "west"):
+		return vips.AlignLow
+	case strings.HasSuffix(position, "east"):
+		return vips.AlignHigh
+	default:
+		return vips.AlignCentre
+	}
+}
@@ -0,0 +1,88 @@
+package operations
+
+import (
+	"fmt"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// TintOperation handles tint(color) filter.
+// The image is converted to grayscale and multiplied by the color, e.g. a sepia or duotone look.
+type TintOperation struct {
+	Color Color
+}
+
+func NewTintOperation() *TintOperation {
+	return &TintOperation{}
+}
+
+func (o *TintOperation) Name() string {
+	return "tint"
+}
+
+func (o *TintOperation) Aliases() []string {
+	return []string{}
+}
+
+func (o *TintOperation) Clone() Operation {
+	return NewTintOperation()
+}
+
+func (o *TintOperation) Stage() Stage {
+	return StagePostResize
+}
+
+func (o *TintOperation) Parse(filter string) (bool, error) {
+	if !matchesFilter(filter, o.Name(), o.Aliases()) {
+		return false, nil
+	}
+
+	args, err := filterArgs(filter, o.Name())
+	if err != nil {
+		return false, err
+	}
+	if len(args) != 1 {
+		return false, fmt.Errorf("tint filter expects 1 parameter (color), got %d", len(args))
+	}
+
+	if o.Color, err = ParseColor(args[0]); err != nil {
+		return false, fmt.Errorf("invalid tint color: %w", err)
+	}
+
+	return true, nil
+}
+
+// Validate checks that the tint color is opaque - its alpha would have no meaning
+func (o *TintOperation) Validate() error {
+	if !o.Color.IsOpaque() {
+		return fmt.Errorf("tint color must be opaque, got: %s", o.Color)
+	}
+	return nil
+}
+
+func (o *TintOperation) Apply(img *vips.Image) (*vips.Image, error) {
+	// Grayscale first, then back to sRGB so every colour band holds the luminance
+	if err := img.Colourspace(vips.InterpretationBW, nil); err != nil {
+		return nil, fmt.Errorf("failed to tint image: %w", err)
+	}
+	if err := img.Colourspace(vips.InterpretationSrgb, nil); err != nil {
+		return nil, fmt.Errorf("failed to tint image: %w", err)
+	}
+
+	multiply := make([]float64, img.Bands())
+	for i := range multiply {
+		multiply[i] = 1
+	}
+	multiply[0] = float64(o.Color.R) / 255
+	multiply[1] = float64(o.Color.G) / 255
+	multiply[2] = float64(o.Color.B) / 255
+
+	format := img.BandFormat()
+	if err := img.Linear(multiply, make([]float64, img.Bands()), nil); err != nil {
+		return nil, fmt.Errorf("failed to tint image: %w", err)
+	}
+	if err := img.Cast(format, nil); err != nil {
+		return nil, fmt.Errorf("failed to tint image: %w", err)
+	}
+	return img, nil
+}
@@ -0,0 +1,120 @@
+package operations
+
+import (
+	"fmt"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// EncoderToggleOperation handles on/off encoder filters such as progressive() and
+// progressive(false). Each filter is a thin constructor below that sets its name and the
+// EncodeOptions field it controls.
+type EncoderToggleOperation struct {
+	Enabled bool
+
+	name    string
+	aliases []string
+	set     func(options *EncodeOptions, enabled bool)
+}
+
+// NewProgressiveOperation handles progressive() filter.
+// Encodes JPEG as progressive and PNG/GIF as interlaced, so browsers can show a preview early.
+func NewProgressiveOperation() *EncoderToggleOperation {
+	return newEncoderToggleOperation("progressive", []string{"interlace"}, func(options *EncodeOptions, enabled bool) {
+		options.Progressive = enabled
+	})
+}
+
+// NewOptimizeOperation handles optimize() filter.
+// Enables mozjpeg-style trellis quantisation and optimised Huffman coding for smaller JPEGs.
+func NewOptimizeOperation() *EncoderToggleOperation {
+	return newEncoderToggleOperation("optimize", nil, func(options *EncodeOptions, enabled bool) {
+		options.Optimize = enabled
+	})
+}
+
+// NewLosslessOperation handles lossless() filter.
+// Encodes WebP, AVIF, HEIC and JPEG XL without loss; quality is then ignored.
+func NewLosslessOperation() *EncoderToggleOperation {
+	return newEncoderToggleOperation("lossless", nil, func(options *EncodeOptions, enabled bool) {
+		options.Lossless = enabled
+	})
+}
+
+// NewPaletteOperation handles palette() filter.
+// Quantises PNG output to an 8-bit palette with libimagequant; quality controls the quantiser.
+func NewPaletteOperation() *EncoderToggleOperation {
+	return newEncoderToggleOperation("palette", nil, func(options *EncodeOptions, enabled bool) {
+		options.Palette = enabled
+	})
+}
+
+func newEncoderToggleOperation(name string, aliases []string, set func(*EncodeOptions, bool)) *EncoderToggleOperation {
+	return &EncoderToggleOperation{
+		Enabled: true,
+		name:    name,
+		aliases: aliases,
+		set:     set,
+	}
+}
+
+func (o *EncoderToggleOperation) Name() string {
+	return o.name
+}
+
+func (o *EncoderToggleOperation) Aliases() []string {
+	if o.aliases == nil {
+		return []string{}
+	}
+	return o.aliases
+}
+
+func (o *EncoderToggleOperation) Clone() Operation {
+	return newEncoderToggleOperation(o.name, o.aliases, o.set)
+}
+
+func (o *EncoderToggleOperation) Parse(filter string) (bool, error) {
+	if !matchesFilter(filter, o.Name(), o.Aliases()) {
+		return false, nil
+	}
+
+	args, err := filterArgs(filter, o.Name())
+	if err != nil {
+		return false, err
+	}
+
+	if o.Enabled, err = parseToggleArgs(o.Name(), args); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ApplyEncoding overrides the server default
+func (o *EncoderToggleOperation) ApplyEncoding(options *EncodeOptions) {
+	o.set(options, o.Enabled)
+}
+
+func (o *EncoderToggleOperation) Apply(img *vips.Image) (*vips.Image, error) {
+	// Encoder toggles are applied during export
+	return img, nil
+}
+
+// parseToggleArgs parses the optional on/off argument of filters such as progressive().
+// No argument switches the option on; false or 0 switches off a server default.
+func parseToggleArgs(name string, args []string) (bool, error) {
+	switch {
+	case len(args) == 0:
+		return true, nil
+	case len(args) > 1:
+		return false, fmt.Errorf("%s filter expects at most 1 parameter (true or false), got %d", name, len(args))
+	}
+
+	switch args[0] {
+	case "true", "1", "":
+		return true, nil
+	case "false", "0":
+		return false, nil
+	default:
+		return false, fmt.Errorf("%s must be true or false, got: %s", name, args[0])
+	}
+}
@@ -0,0 +1,69 @@
+package operations
+
+import (
+	"fmt"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// Tonal adjustment bounds shared by brightness, contrast and saturation
+const (
+	minToneAdjustment = -100
+	maxToneAdjustment = 100
+)
+
+// parseToneArg parses the single percentage argument of a tonal filter
+func parseToneArg(filter, name string) (float64, error) {
+	args, err := filterArgs(filter, name)
+	if err != nil {
+		return 0, err
+	}
+	if len(args) != 1 {
+		return 0, fmt.Errorf("%s filter expects 1 parameter (-100..100), got %d", name, len(args))
+	}
+	return parseFloatArg(name, args[0])
+}
+
+// validateToneArg checks a percentage adjustment is within -100..100
+func validateToneArg(name string, value float64) error {
+	if value < minToneAdjustment || value > maxToneAdjustment {
+		return fmt.Errorf("%s must be between %d and %d, got: %g", name, minToneAdjustment, maxToneAdjustment, value)
+	}
+	return nil
+}
+
+// colourBands returns the number of bands carrying colour, excluding alpha
+func colourBands(img *vips.Image) int {
+	if img.HasAlpha() {
+		return img.Bands() - 1
+	}
+	return img.Bands()
+}
+
+// maxBandValue returns the white level of the image: 65535 for 16-bit, otherwise 255
+func maxBandValue(img *vips.Image) float64 {
+	if interpretation := img.Interpretation(); interpretation == vips.InterpretationRgb16 || interpretation == vips.InterpretationGrey16 {
+		return 65535
+	}
+	return 255
+}
+
+// linearColour calculates a*in + b on the colour bands only, leaving alpha untouched
+// and keeping the band format.
+func linearColour(img *vips.Image, a, b float64) error {
+	multiply := make([]float64, img.Bands())
+	add := make([]float64, img.Bands())
+	for i := range multiply {
+		multiply[i] = 1
+	}
+	for i := range colourBands(img) {
+		multiply[i] = a
+		add[i] = b
+	}
+
+	format := img.BandFormat()
+	if err := img.Linear(multiply, add, nil); err != nil {
+		return err
+	}
+	return img.Cast(format, nil)
+}
@@ -0,0 +1,94 @@
+package operations
+
+import (
+	"fmt"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// Trim threshold bounds, as a difference from the border colour on the 0..255 scale
+const (
+	minTrimThreshold = 0
+	maxTrimThreshold = 255
+)
+
+// TrimOperation handles trim([threshold]) filter.
+// It removes uniform borders whose colour matches the top-left pixel.
+type TrimOperation struct {
+	Threshold float64
+}
+
+func NewTrimOperation() *TrimOperation {
+	return &TrimOperation{
+		Threshold: 10,
+	}
+}
+
+func (o *TrimOperation) Name() string {
+	return "trim"
+}
+
+func (o *TrimOperation) Aliases() []string {
+	return []string{}
+}
+
+func (o *TrimOperation) Clone() Operation {
+	return NewTrimOperation()
+}
+
+func (o *TrimOperation) Parse(filter string) (bool, error) {
+	if !matchesFilter(filter, o.Name(), o.Aliases()) {
+		return false, nil
+	}
+
+	args, err := filterArgs(filter, o.Name())
+	if err != nil {
+		return false, err
+	}
+	if len(args) > 1 {
+		return false, fmt.Errorf("trim filter expects 0 or 1 parameters (threshold), got %d", len(args))
+	}
+
+	if len(args) == 1 {
+		if o.Threshold, err = parseFloatArg("trim threshold", args[0]); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// Validate checks that the threshold is within the supported range
+func (o *TrimOperation) Validate() error {
+	if o.Threshold < minTrimThreshold || o.Threshold > maxTrimThreshold {
+		return fmt.Errorf("trim threshold must be between %d and %d, got: %g", minTrimThreshold, maxTrimThreshold, o.Threshold)
+	}
+	return nil
+}
+
+func (o *TrimOperation) Apply(img *vips.Image) (*vips.Image, error) {
+	// The top-left pixel defines the border colour; alpha is flattened by find_trim
+	pixel, err := img.Getpoint(0, 0, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample border colour: %w", err)
+	}
+	background := pixel[:min(colourBands(img), len(pixel))]
+
+	left, top, width, height, err := img.FindTrim(&vips.FindTrimOptions{
+		Threshold:  o.Threshold,
+		Background: background,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find trim area: %w", err)
+	}
+
+	// Nothing but border (uniform image) or nothing to trim
+	if width <= 0 || height <= 0 || (width == img.Width() && height == img.Height()) {
+		return img, nil
+	}
+
+	if err := img.ExtractArea(left, top, width, height); err != nil {
+		return nil, fmt.Errorf("failed to trim image: %w", err)
+	}
+	return img, nil
+}
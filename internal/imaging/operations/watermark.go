@@ -0,0 +1,218 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cshum/vipsgen/vips"
+)
+
+// WatermarkOperation handles watermark(path[,position[,opacity[,scale]]]) filter.
+// The overlay image is fetched from storage and composited onto the output image.
+type WatermarkOperation struct {
+	Path     string  // Storage key of the overlay image
+	Position string  // Compass gravity (default "south-east")
+	Opacity  float64 // 0..1 (default 1)
+	Scale    float64 // Overlay width relative to the image width, 0 keeps the overlay size
+
+	data []byte // Overlay image, set by LoadResources
+}
+
+func NewWatermarkOperation() *WatermarkOperation {
+	return &WatermarkOperation{
+		Position: "south-east",
+		Opacity:  1,
+	}
+}
+
+func (o *WatermarkOperation) Name() string {
+	return "watermark"
+}
+
+func (o *WatermarkOperation) Aliases() []string {
+	return []string{"wm"}
+}
+
+func (o *WatermarkOperation) Clone() Operation {
+	return NewWatermarkOperation()
+}
+
+// Stage composites the watermark last, so it is not resized or blurred with the image
+func (o *WatermarkOperation) Stage() Stage {
+	return StageOverlay
+}
+
+func (o *WatermarkOperation) Parse(filter string) (bool, error) {
+	if !matchesFilter(filter, o.Name(), o.Aliases()) {
+		return false, nil
+	}
+
+	args, err := filterArgs(filter, o.Name())
+	if err != nil {
+		return false, err
+	}
+	if len(args) == 0 || len(args) > 4 {
+		return false, fmt.Errorf("watermark filter expects 1 to 4 parameters (path,position,opacity,scale), got %d", len(args))
+	}
+
+	if o.Path, err = parseWatermarkPath(args[0]); err != nil {
+		return false, err
+	}
+
+	if len(args) > 1 && args[1] != "" {
+		position, ok := gravityAliases[strings.ToLower(args[1])]
+		if !ok || position == GravityAttention || position == GravityEntropy {
+			return false, fmt.Errorf("invalid watermark position: %s", args[1])
+		}
+		o.Position = position
+	}
+
+	if len(args) > 2 && args[2] != "" {
+		if o.Opacity, err = parseFloatArg("watermark opacity", args[2]); err != nil {
+			return false, err
+		}
+	}
+
+	if len(args) > 3 && args[3] != "" {
+		if o.Scale, err = parseFloatArg("watermark scale", args[3]); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// parseWatermarkPath returns the storage key. Keys containing "/" use the b64: form.
+func parseWatermarkPath(arg string) (string, error) {
+	path, err := decodeTextArg("watermark path", arg)
+	if err != nil {
+		return "", err
+	}
+
+	path = strings.TrimPrefix(path, "/")
+	if path == "" {
+		return "", fmt.Errorf("watermark path is empty")
+	}
+	return path, nil
+}
+
+// Validate checks opacity and scale ranges
+func (o *WatermarkOperation) Validate() error {
+	if o.Opacity <= 0 || o.Opacity > 1 {
+		return fmt.Errorf("watermark opacity must be greater than 0 and at most 1, got: %g", o.Opacity)
+	}
+	if o.Scale < 0 || o.Scale > 1 {
+		return fmt.Errorf("watermark scale must be between 0 and 1, got: %g", o.Scale)
+	}
+	return nil
+}
+
+// LoadResources fetches the overlay image from storage
+func (o *WatermarkOperation) LoadResources(ctx context.Context, loader ResourceLoader) error {
+	data, err := loader.GetObject(ctx, o.Path)
+	if err != nil {
+		return fmt.Errorf("failed to fetch watermark %s: %w", o.Path, err)
+	}
+	o.data = data
+	return nil
+}
+
+func (o *WatermarkOperation) Apply(img *vips.Image) (*vips.Image, error) {
+	if o.data == nil {
+		return nil, fmt.Errorf("watermark %s was not loaded", o.Path)
+	}
+
+	overlay, err := vips.NewImageFromBuffer(o.data, vips.DefaultLoadOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode watermark %s: %w", o.Path, err)
+	}
+	defer overlay.Close()
+
+	if err := o.scaleOverlay(overlay, img.Width(), img.Height()); err != nil {
+		return nil, err
+	}
+	if err := applyOpacity(overlay, o.Opacity); err != nil {
+		return nil, err
+	}
+
+	if err := compositeOverlay(img, overlay, o.Position); err != nil {
+		return nil, fmt.Errorf("failed to composite watermark: %w", err)
+	}
+	return img, nil
+}
+
+// compositeOverlay blends overlay onto img at the given compass position
+func compositeOverlay(img, overlay *vips.Image, position string) error {
+	left, top := gravityOffset(position, img.Width()-overlay.Width(), img.Height()-overlay.Height())
+
+	hadAlpha := img.HasAlpha()
+	options := vips.DefaultComposite2Options()
+	options.X = left
+	options.Y = top
+	if err := img.Composite2(overlay, vips.BlendModeOver, options); err != nil {
+		return err
+	}
+
+	// Compositing always adds alpha - drop it again so opaque images stay opaque
+	if !hadAlpha && img.HasAlpha() {
+		if err := img.ExtractBand(0, &vips.ExtractBandOptions{N: img.Bands() - 1}); err != nil {
+			return fmt.Errorf("failed to remove alpha channel: %w", err)
+		}
+	}
+	return nil
+}
+
+// scaleOverlay resizes the overlay to the requested relative width, shrinking it further
+// if needed so it never exceeds the image.
+func (o *WatermarkOperation) scaleOverlay(overlay *vips.Image, width, height int) error {
+	overlayWidth, overlayHeight := overlay.Width(), overlay.Height()
+	if overlayWidth <= 0 || overlayHeight <= 0 {
+		return fmt.Errorf("invalid watermark size %dx%d", overlayWidth, overlayHeight)
+	}
+
+	scale := 1.0
+	if o.Scale > 0 {
+		scale = o.Scale * float64(width) / float64(overlayWidth)
+	}
+	scale = min(scale, float64(width)/float64(overlayWidth), float64(height)/float64(overlayHeight))
+	if scale == 1 {
+		return nil
+	}
+
+	options := vips.DefaultResizeOptions()
+	options.Vscale = scale
+	if err := overlay.Resize(scale, options); err != nil {
+		return fmt.Errorf("failed to resize watermark: %w", err)
+	}
+	return nil
+}
+
+// applyOpacity adds an alpha channel if missing and multiplies it by opacity
+func applyOpacity(overlay *vips.Image, opacity float64) error {
+	if !overlay.HasAlpha() {
+		if err := overlay.Addalpha(); err != nil {
+			return fmt.Errorf("failed to add alpha channel: %w", err)
+		}
+	}
+	if opacity >= 1 {
+		return nil
+	}
+
+	bands := overlay.Bands()
+	multiply := make([]float64, bands)
+	add := make([]float64, bands)
+	for i := range multiply {
+		multiply[i] = 1
+	}
+	multiply[bands-1] = opacity
+
+	format := overlay.BandFormat()
+	if err := overlay.Linear(multiply, add, nil); err != nil {
+		return fmt.Errorf("failed to apply opacity: %w", err)
+	}
+	if err := overlay.Cast(format, nil); err != nil {
+		return fmt.Errorf("failed to apply opacity: %w", err)
+	}
+	return nil
+}
@@ -37,8 +37,10 @@ type DiskCache struct {
 	MaxItems int
 
 	mu          sync.Mutex
+	writeMu     sync.RWMutex // Held shared by writers for the whole write, exclusively by Clear
 	currentSize atomic.Int64
 	lru         *simplelru.LRU[string, *cacheEntry]
+	tags        map[string]map[string]struct{} // tag hash -> set of entry hashes
 	cleanupWake chan struct{}
 	deleteQueue chan string
 	cleanupPos  int
@@ -46,6 +48,7 @@ type DiskCache struct {
 
 type cacheEntry struct {
 	hash      string
+	tags      []string // Optional tag hashes used for group invalidation
 	path      string
 	size      int64
 	expiresAt time.Time
@@ -155,11 +158,24 @@ func (dc *DiskCache) Get(key string) ([]byte, error) {
 
 // Set stores a cached item by key with TTL.
 func (dc *DiskCache) Set(key string, data []byte) error {
+	return dc.SetTagged(key, nil, data)
+}
+
+// SetTagged stores a cached item by key with TTL and associates it with tags,
+// so that all entries sharing a tag can later be removed with DeleteTag.
+// The tags are encoded in the filename and survive restarts.
+func (dc *DiskCache) SetTagged(key string, tags []string, data []byte) error {
 	dc.notifyActivity()
 
+	// Clear must not remove the directory tree while a file is being written
+	// or index an entry whose file it has just removed.
+	dc.writeMu.RLock()
+	defer dc.writeMu.RUnlock()
+
 	expiresAt := time.Now().Add(dc.TTL)
 	hash := dc.getHash(key)
-	filePath := dc.getFilePathWithExpiration(hash, expiresAt)
+	tagHashes := dc.getTagHashes(tags)
+	filePath := dc.getFilePathWithExpiration(hash, tagHashes, expiresAt)
 
 	if err := atomicWriteFile(filePath, data); err != nil {
 		return err
@@ -167,6 +183,7 @@ func (dc *DiskCache) Set(key string, data []byte) error {
 
 	dc.updateLRUEntry(&cacheEntry{
 		hash:      hash,
+		tags:      tagHashes,
 		path:      filePath,
 		size:      int64(len(data)),
 		expiresAt: expiresAt,
@@ -187,8 +204,34 @@ func (dc *DiskCache) Delete(key string) error {
 	return nil
 }
 
+// DeleteTag removes all cache entries associated with tag and returns how many were removed.
+func (dc *DiskCache) DeleteTag(tag string) int {
+	dc.notifyActivity()
+
+	tagHash := dc.getTagHash(tag)
+	if tagHash == "" {
+		return 0
+	}
+
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	removed := 0
+	for hash := range dc.tags[tagHash] {
+		if dc.lru.Remove(hash) {
+			removed++
+		}
+	}
+	delete(dc.tags, tagHash)
+	return removed
+}
+
 // Clear removes all cache entries.
+// It waits for in-flight writes to finish and blocks new ones until the directory is recreated.
 func (dc *DiskCache) Clear() error {
+	dc.writeMu.Lock()
+	defer dc.writeMu.Unlock()
+
 	dc.mu.Lock()
 	defer dc.mu.Unlock()
 
@@ -25,13 +25,27 @@ func prepareCacheDir(basePath string) (string, error) {
 	return absPath, nil
 }
 
-// atomicWriteFile writes data to a temp file and atomically renames it into place.
+// atomicWriteFile writes data to a uniquely named temp file and atomically renames it into place,
+// so concurrent writers of the same key never share a temp file.
 func atomicWriteFile(path string, data []byte) error {
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create cache directory structure: %w", err)
 	}
-	tmp := path + ".tmp"
-	if err := os.WriteFile(tmp, data, 0644); err != nil {
+	f, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create cache file: %w", err)
+	}
+	tmp := f.Name()
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp, 0644)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
 		return fmt.Errorf("failed to write cache file: %w", err)
 	}
 	if err := os.Rename(tmp, path); err != nil {
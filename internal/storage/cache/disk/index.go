@@ -19,7 +19,15 @@ func (dc *DiskCache) loadIndexFromDisk() {
 	deletedCount := 0
 
 	_ = filepath.Walk(dc.basePath, func(path string, info os.FileInfo, err error) error {
-		if err != nil || info.IsDir() || filepath.Ext(path) != ".cache" {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		// Leftover temp files from writes interrupted by a crash or shutdown
+		if filepath.Ext(path) == ".tmp" {
+			_ = os.Remove(path)
+			return nil
+		}
+		if filepath.Ext(path) != ".cache" {
 			return nil
 		}
 		totalScanned++
@@ -36,7 +44,7 @@ func (dc *DiskCache) loadIndexFromDisk() {
 // processIndexFile attempts to index a single cache file.
 // Returns true if the file was removed (expired or unparseable), false if it was indexed.
 func (dc *DiskCache) processIndexFile(path string, info os.FileInfo, now time.Time) (deleted bool) {
-	hash, expiresAt, err := dc.parseCacheFilename(filepath.Base(path))
+	hash, tagHashes, expiresAt, err := dc.parseCacheFilename(filepath.Base(path))
 	if err != nil || now.After(expiresAt) {
 		if removeErr := os.Remove(path); removeErr == nil || os.IsNotExist(removeErr) {
 			deleted = true
@@ -47,6 +55,7 @@ func (dc *DiskCache) processIndexFile(path string, info os.FileInfo, now time.Ti
 
 	dc.updateLRUEntry(&cacheEntry{
 		hash:      hash,
+		tags:      tagHashes,
 		path:      path,
 		size:      info.Size(),
 		expiresAt: expiresAt,
@@ -16,26 +16,62 @@ func (dc *DiskCache) initLRU() {
 			return
 		}
 		dc.currentSize.Add(-entry.size)
+		dc.untagLocked(entry)
 		dc.enqueueDelete(entry.path)
 	})
 	if err != nil {
 		panic(fmt.Sprintf("failed to initialize LRU index: %v", err))
 	}
 	dc.lru = lruIndex
+	dc.tags = make(map[string]map[string]struct{})
 }
 
 // updateLRUEntry registers or replaces an entry in the LRU index, then evicts if over the size limit.
+// When the previous entry points to the same file (rewritten within the same second), it is
+// updated in place so the eviction callback does not delete the file that was just written.
 func (dc *DiskCache) updateLRUEntry(entry *cacheEntry) {
 	dc.mu.Lock()
-	if _, exists := dc.lru.Peek(entry.hash); exists {
-		dc.lru.Remove(entry.hash)
+	if existing, exists := dc.lru.Peek(entry.hash); exists {
+		if existing.path == entry.path {
+			dc.currentSize.Add(-existing.size)
+			dc.untagLocked(existing)
+		} else {
+			dc.lru.Remove(entry.hash)
+		}
 	}
 	dc.currentSize.Add(entry.size)
 	dc.lru.Add(entry.hash, entry)
+	dc.tagLocked(entry)
 	dc.evictForSizeLocked(2048)
 	dc.mu.Unlock()
 }
 
+// tagLocked adds an entry to the tag index. Must be called with dc.mu held.
+func (dc *DiskCache) tagLocked(entry *cacheEntry) {
+	for _, tag := range entry.tags {
+		hashes, ok := dc.tags[tag]
+		if !ok {
+			hashes = make(map[string]struct{})
+			dc.tags[tag] = hashes
+		}
+		hashes[entry.hash] = struct{}{}
+	}
+}
+
+// untagLocked removes an entry from the tag index. Must be called with dc.mu held.
+func (dc *DiskCache) untagLocked(entry *cacheEntry) {
+	for _, tag := range entry.tags {
+		hashes, ok := dc.tags[tag]
+		if !ok {
+			continue
+		}
+		delete(hashes, entry.hash)
+		if len(hashes) == 0 {
+			delete(dc.tags, tag)
+		}
+	}
+}
+
 // removeStaleLRUEntry removes the LRU entry for hash if it still points to the given path.
 func (dc *DiskCache) removeStaleLRUEntry(hash, path string) {
 	dc.mu.Lock()
@@ -21,6 +21,27 @@ func (dc *DiskCache) getHash(key string) string {
 	return hex.EncodeToString(hash[:])
 }
 
+// getTagHash generates a short BLAKE3 hash of a tag, embedded in cache filenames so the
+// tag index can be rebuilt from disk on startup.
+func (dc *DiskCache) getTagHash(tag string) string {
+	if tag == "" {
+		return ""
+	}
+	hash := blake3.Sum256([]byte(tag))
+	return hex.EncodeToString(hash[:8])
+}
+
+// getTagHashes hashes each non-empty tag with getTagHash.
+func (dc *DiskCache) getTagHashes(tags []string) []string {
+	var hashes []string
+	for _, tag := range tags {
+		if tagHash := dc.getTagHash(tag); tagHash != "" {
+			hashes = append(hashes, tagHash)
+		}
+	}
+	return hashes
+}
+
 // getDirPath generates a hierarchical directory path using nginx-style levels=2:2
 // to limit files per directory.
 func (dc *DiskCache) getDirPath(hashStr string) string {
@@ -30,22 +51,32 @@ func (dc *DiskCache) getDirPath(hashStr string) string {
 
 // getFilePathWithExpiration generates a cache file path with the expiry timestamp encoded in the name.
 // Format: basePath/f1/8e/{hash}_{unixTimestamp}.cache
-func (dc *DiskCache) getFilePathWithExpiration(hashStr string, expiresAt time.Time) string {
-	return filepath.Join(dc.getDirPath(hashStr), fmt.Sprintf("%s_%d.cache", hashStr, expiresAt.Unix()))
+// Tagged entries: basePath/f1/8e/{hash}-{tagHash}[-{tagHash}...]_{unixTimestamp}.cache
+func (dc *DiskCache) getFilePathWithExpiration(hashStr string, tagHashes []string, expiresAt time.Time) string {
+	name := hashStr
+	for _, tagHash := range tagHashes {
+		name += "-" + tagHash
+	}
+	return filepath.Join(dc.getDirPath(hashStr), fmt.Sprintf("%s_%d.cache", name, expiresAt.Unix()))
 }
 
-// parseCacheFilename extracts hash and expiration timestamp from a cache filename.
-// Format: {hash}_{unixTimestamp}.cache
-func (dc *DiskCache) parseCacheFilename(filename string) (string, time.Time, error) {
+// parseCacheFilename extracts hash, optional tag hashes and expiration timestamp from a cache filename.
+// Format: {hash}[-{tagHash}...]_{unixTimestamp}.cache
+func (dc *DiskCache) parseCacheFilename(filename string) (hash string, tagHashes []string, expiresAt time.Time, err error) {
 	name := strings.TrimSuffix(filename, ".cache")
 	lastUnderscore := strings.LastIndex(name, "_")
 	if lastUnderscore == -1 {
-		return "", time.Time{}, fmt.Errorf("invalid filename format: %s", filename)
+		return "", nil, time.Time{}, fmt.Errorf("invalid filename format: %s", filename)
 	}
 
 	timestamp, err := strconv.ParseInt(name[lastUnderscore+1:], 10, 64)
 	if err != nil {
-		return "", time.Time{}, fmt.Errorf("invalid timestamp in filename: %w", err)
+		return "", nil, time.Time{}, fmt.Errorf("invalid timestamp in filename: %w", err)
+	}
+
+	hash, tags, _ := strings.Cut(name[:lastUnderscore], "-")
+	if tags != "" {
+		tagHashes = strings.Split(tags, "-")
 	}
-	return name[:lastUnderscore], time.Unix(timestamp, 0), nil
+	return hash, tagHashes, time.Unix(timestamp, 0), nil
 }
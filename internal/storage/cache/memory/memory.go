@@ -2,15 +2,28 @@ package memory
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/dgraph-io/ristretto"
+	"github.com/dgraph-io/ristretto/z"
 	"github.com/sashko-guz/mage/internal/pkg/logger"
 )
 
 // MemoryCache provides high-performance in-memory caching with LRU eviction
 type MemoryCache struct {
 	cache *ristretto.Cache
+
+	// Tag index for group invalidation. Ristretto only reports hashed keys on eviction,
+	// so tagged keys are also indexed by their hash to keep the index in sync.
+	tagMu      sync.Mutex
+	tags       map[string]map[string]struct{} // tag -> set of keys
+	taggedKeys map[uint64]taggedKey           // key hash -> key and tags
+}
+
+type taggedKey struct {
+	key  string
+	tags []string
 }
 
 // Config defines configuration for the memory cache
@@ -37,6 +50,11 @@ func New(cfg Config) (*MemoryCache, error) {
 		cfg.BufferItems = max(cfg.MaxItems*10, 1000)
 	}
 
+	mc := &MemoryCache{
+		tags:       make(map[string]map[string]struct{}),
+		taggedKeys: make(map[uint64]taggedKey),
+	}
+
 	cache, err := ristretto.NewCache(&ristretto.Config{
 		NumCounters: cfg.BufferItems, // Number of keys to track frequency (10x expected items)
 		MaxCost:     cfg.MaxSize,     // Max memory usage in bytes
@@ -44,18 +62,21 @@ func New(cfg Config) (*MemoryCache, error) {
 		Metrics:     true,            // Enable metrics collection
 		OnEvict: func(item *ristretto.Item) {
 			logger.Debugf("[MemoryCache] Evicted item (cost: %d bytes)", item.Cost)
+			mc.untagHash(item.Key)
+		},
+		OnReject: func(item *ristretto.Item) {
+			mc.untagHash(item.Key)
 		},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create ristretto cache: %w", err)
 	}
+	mc.cache = cache
 
 	logger.Infof("[MemoryCache] Initialized: MaxSize=%dMB, MaxItems=%d, TTL=%v",
 		cfg.MaxSize/(1024*1024), cfg.MaxItems, cfg.TTL)
 
-	return &MemoryCache{
-		cache: cache,
-	}, nil
+	return mc, nil
 }
 
 // Get retrieves a value from the cache.
@@ -91,17 +112,97 @@ func (mc *MemoryCache) Set(key string, data []byte, ttl time.Duration) bool {
 	return success
 }
 
+// SetTagged stores a value in the cache with the specified TTL and associates it with tags,
+// so that all entries sharing a tag can later be removed with DeleteTag.
+func (mc *MemoryCache) SetTagged(key string, tags []string, data []byte, ttl time.Duration) bool {
+	keyHash, _ := z.KeyToHash(key)
+
+	mc.tagMu.Lock()
+	// Drop the previous tags, so DeleteTag of those tags does not remove the new entry
+	mc.untagHashLocked(keyHash)
+	var kept []string
+	for _, tag := range tags {
+		if tag == "" {
+			continue
+		}
+		keys, ok := mc.tags[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			mc.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+		kept = append(kept, tag)
+	}
+	if len(kept) > 0 {
+		mc.taggedKeys[keyHash] = taggedKey{key: key, tags: kept}
+	}
+	mc.tagMu.Unlock()
+
+	return mc.Set(key, data, ttl)
+}
+
 // Delete removes a key from the cache.
 func (mc *MemoryCache) Delete(key string) {
+	keyHash, _ := z.KeyToHash(key)
+	mc.untagHash(keyHash)
 	mc.cache.Del(key)
 }
 
+// DeleteTag removes all entries associated with tag and returns how many keys were removed.
+func (mc *MemoryCache) DeleteTag(tag string) int {
+	mc.tagMu.Lock()
+	keys := mc.tags[tag]
+	delete(mc.tags, tag)
+	for key := range keys {
+		// Also drops the key from its other tags
+		keyHash, _ := z.KeyToHash(key)
+		mc.untagHashLocked(keyHash)
+	}
+	mc.tagMu.Unlock()
+
+	for key := range keys {
+		mc.cache.Del(key)
+	}
+	return len(keys)
+}
+
 // Clear removes all entries from the cache.
 func (mc *MemoryCache) Clear() {
 	mc.cache.Clear()
+
+	mc.tagMu.Lock()
+	mc.tags = make(map[string]map[string]struct{})
+	mc.taggedKeys = make(map[uint64]taggedKey)
+	mc.tagMu.Unlock()
+
 	logger.Infof("[MemoryCache] Cache cleared")
 }
 
+// untagHash removes the entry with the given key hash from the tag index.
+func (mc *MemoryCache) untagHash(keyHash uint64) {
+	mc.tagMu.Lock()
+	defer mc.tagMu.Unlock()
+	mc.untagHashLocked(keyHash)
+}
+
+// untagHashLocked is untagHash for callers holding tagMu.
+func (mc *MemoryCache) untagHashLocked(keyHash uint64) {
+	tk, ok := mc.taggedKeys[keyHash]
+	if !ok {
+		return
+	}
+	delete(mc.taggedKeys, keyHash)
+
+	for _, tag := range tk.tags {
+		if keys, ok := mc.tags[tag]; ok {
+			delete(keys, tk.key)
+			if len(keys) == 0 {
+				delete(mc.tags, tag)
+			}
+		}
+	}
+}
+
 // Wait blocks until all pending writes are processed.
 // This is useful before closing to ensure all Sets are committed.
 func (mc *MemoryCache) Wait() {
@@ -2,6 +2,8 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -11,10 +13,22 @@ import (
 	"golang.org/x/sync/singleflight"
 )
 
+// ErrSourceUndecodable is returned by GetObject when a source is remembered in the
+// negative cache as an image that could not be decoded.
+var ErrSourceUndecodable = errors.New("source image cannot be decoded")
+
+// Negative cache entry payloads, recording why a source was remembered as unusable.
+const (
+	negativeReasonNotFound    = "not_found"
+	negativeReasonUndecodable = "undecodable"
+)
+
 // cacheWriteTask represents a single cache write operation
 type cacheWriteTask struct {
 	key  string
+	tags []string
 	data []byte
+	seq  uint64 // Queue order of thumbnail writes, compared against purges
 }
 
 // MetricsRecorder interface for recording cache metrics
@@ -41,6 +55,11 @@ type CachedStorage struct {
 	thumbDiskCache   *cache.DiskCache
 	thumbTTL         time.Duration
 
+	// Negative caching for missing and undecodable sources
+	negativeMemoryCache *cache.MemoryCache
+	negativeDiskCache   *cache.DiskCache
+	negativeTTL         time.Duration
+
 	// Deduplicates concurrent source fetches for the same path
 	sourceFlight singleflight.Group
 
@@ -52,6 +71,14 @@ type CachedStorage struct {
 	thumbWriteQueue chan cacheWriteTask
 	thumbWriteMu    sync.WaitGroup
 
+	// Keeps thumbnail writes queued before a purge from re-populating the disk cache.
+	// Writers hold purgeMu shared while checking and writing, PurgeSource holds it exclusively.
+	purgeMu       sync.RWMutex
+	pendingMu     sync.Mutex
+	thumbWriteSeq uint64            // Sequence of the last queued thumbnail write
+	pendingWrites map[string]int    // Tag -> number of queued thumbnail writes
+	purgedSeq     map[string]uint64 // Tag -> queued writes up to this sequence were purged
+
 	// Metrics recorder (optional)
 	metrics    MetricsRecorder
 	driverName string
@@ -67,6 +94,11 @@ func (cs *CachedStorage) ThumbsCacheEnabled() bool {
 	return cs.thumbMemoryCache != nil || cs.thumbDiskCache != nil
 }
 
+// NegativeCacheEnabled returns true if any negative cache layer is enabled
+func (cs *CachedStorage) NegativeCacheEnabled() bool {
+	return cs.negativeMemoryCache != nil || cs.negativeDiskCache != nil
+}
+
 // SetMetrics sets the metrics recorder for cache statistics
 func (cs *CachedStorage) SetMetrics(m MetricsRecorder, driverName string) {
 	cs.metrics = m
@@ -92,15 +124,24 @@ func (cs *CachedStorage) recordStorageOp(operation string, duration float64) {
 }
 
 // GetObject retrieves a source image through the multi-layer cache hierarchy
-// 1. Check source memory cache (fastest)
-// 2. Check source disk cache
-// 3. Fetch from underlying storage and populate caches
+// 1. Check negative cache for sources known to be missing or undecodable
+// 2. Check source memory cache (fastest)
+// 3. Check source disk cache
+// 4. Fetch from underlying storage and populate caches
 func (cs *CachedStorage) GetObject(ctx context.Context, key string) ([]byte, error) {
 	cacheKey := "source:" + key
 
+	if err := cs.checkNegative(key); err != nil {
+		return nil, err
+	}
+
 	// If sources caching is disabled, bypass all cache logic
 	if !cs.SourcesCacheEnabled() {
-		return cs.underlying.GetObject(ctx, key)
+		data, err := cs.underlying.GetObject(ctx, key)
+		if err != nil {
+			cs.rememberMissing(key, err)
+		}
+		return data, err
 	}
 
 	// Layer 1: Check source memory cache first (if enabled)
@@ -143,6 +184,7 @@ func (cs *CachedStorage) GetObject(ctx context.Context, key string) ([]byte, err
 		return data, err
 	})
 	if err != nil {
+		cs.rememberMissing(key, err)
 		return nil, err
 	}
 	data := result.([]byte)
@@ -159,6 +201,88 @@ func (cs *CachedStorage) GetObject(ctx context.Context, key string) ([]byte, err
 	return data, nil
 }
 
+// checkNegative returns an error if the source is remembered in the negative cache.
+// The returned error wraps the same sentinel the original failure did, so callers
+// produce the same response for cached and uncached misses.
+func (cs *CachedStorage) checkNegative(key string) error {
+	if !cs.NegativeCacheEnabled() {
+		return nil
+	}
+
+	negativeKey := "negative:source:" + key
+
+	var reason []byte
+	found := false
+
+	if cs.negativeMemoryCache != nil {
+		if reason, found = cs.negativeMemoryCache.Get(negativeKey); found {
+			cs.recordHit("negative", "memory")
+		} else {
+			cs.recordMiss("negative", "memory")
+		}
+	}
+
+	if !found && cs.negativeDiskCache != nil {
+		if data, err := cs.negativeDiskCache.Get(negativeKey); err == nil {
+			reason, found = data, true
+			cs.recordHit("negative", "disk")
+
+			// Populate negative memory cache for next time
+			if cs.negativeMemoryCache != nil {
+				cs.negativeMemoryCache.Set(negativeKey, data, cs.negativeTTL)
+			}
+		} else {
+			cs.recordMiss("negative", "disk")
+		}
+	}
+
+	if !found {
+		return nil
+	}
+
+	logger.Debugf("[CachedStorage] Negative cache HIT for key: %s (reason=%s)", key, reason)
+	if string(reason) == negativeReasonUndecodable {
+		return fmt.Errorf("%w: %s", ErrSourceUndecodable, key)
+	}
+	return fmt.Errorf("%w: %s", drivers.ErrNotFound, key)
+}
+
+// rememberMissing records a negative cache entry when the underlying storage reports
+// that the source does not exist. Other failures (timeouts, outages) are not cached.
+func (cs *CachedStorage) rememberMissing(key string, err error) {
+	if errors.Is(err, drivers.ErrNotFound) {
+		cs.setNegative(key, negativeReasonNotFound)
+	}
+}
+
+// SetSourceUndecodable records a negative cache entry for a source that was fetched
+// successfully but could not be decoded as an image.
+func (cs *CachedStorage) SetSourceUndecodable(key string) {
+	cs.setNegative(key, negativeReasonUndecodable)
+}
+
+// setNegative stores a negative cache entry in all enabled negative cache layers.
+// Entries are a few bytes, so the disk write happens synchronously.
+func (cs *CachedStorage) setNegative(key, reason string) {
+	if !cs.NegativeCacheEnabled() {
+		return
+	}
+
+	negativeKey := "negative:source:" + key
+
+	if cs.negativeMemoryCache != nil {
+		cs.negativeMemoryCache.Set(negativeKey, []byte(reason), cs.negativeTTL)
+	}
+
+	if cs.negativeDiskCache != nil {
+		if err := cs.negativeDiskCache.Set(negativeKey, []byte(reason)); err != nil {
+			logger.Errorf("[CachedStorage] Error writing negative entry to disk cache: %v", err)
+		}
+	}
+
+	logger.Debugf("[CachedStorage] Cached negative entry: %s (reason=%s)", key, reason)
+}
+
 // initSourceWorkers starts worker goroutines for asynchronous source cache writes
 func (cs *CachedStorage) initSourceWorkers(numWorkers, queueSize int) {
 	if numWorkers <= 0 {
@@ -253,7 +377,9 @@ func (cs *CachedStorage) GetThumbnail(cacheKey string) ([]byte, bool, error) {
 
 // SetThumbnail stores a thumbnail in the thumb caches (memory only, synchronously)
 // Disk writes happen asynchronously via SetThumbnailAsync
-func (cs *CachedStorage) SetThumbnail(cacheKey string, data []byte) error {
+// tags are the source path and the paths of overlay resources (watermarks), so the entry is
+// purged together with any of them via PurgeSource
+func (cs *CachedStorage) SetThumbnail(cacheKey string, tags []string, data []byte) error {
 	// If thumbs caching is disabled, don't store anything
 	if !cs.ThumbsCacheEnabled() {
 		return nil
@@ -263,7 +389,7 @@ func (cs *CachedStorage) SetThumbnail(cacheKey string, data []byte) error {
 
 	// Store in memory cache synchronously (fast, blocking only on memory allocation)
 	if cs.thumbMemoryCache != nil {
-		cs.thumbMemoryCache.Set(thumbnailKey, data, cs.thumbTTL)
+		cs.thumbMemoryCache.SetTagged(thumbnailKey, tags, data, cs.thumbTTL)
 	}
 
 	// Async disk write happens separately via SetThumbnailAsync
@@ -293,18 +419,74 @@ func (cs *CachedStorage) thumbWriter() {
 	defer cs.thumbWriteMu.Done()
 
 	for task := range cs.thumbWriteQueue {
-		if cs.thumbDiskCache != nil {
-			if err := cs.thumbDiskCache.Set(task.key, task.data); err != nil {
+		cs.purgeMu.RLock()
+		if cs.thumbDiskCache != nil && !cs.thumbWritePurged(task) {
+			if err := cs.thumbDiskCache.SetTagged(task.key, task.tags, task.data); err != nil {
 				logger.Errorf("[CachedStorage] Error writing thumbnail to disk cache: %v", err)
 			}
 		}
+		cs.purgeMu.RUnlock()
+		cs.finishThumbWrite(task)
+	}
+}
+
+// queueThumbWrite assigns the next sequence to a thumbnail write and counts it as pending for its tags
+func (cs *CachedStorage) queueThumbWrite(task *cacheWriteTask) {
+	cs.pendingMu.Lock()
+	defer cs.pendingMu.Unlock()
+
+	if cs.pendingWrites == nil {
+		cs.pendingWrites = make(map[string]int)
+		cs.purgedSeq = make(map[string]uint64)
+	}
+	cs.thumbWriteSeq++
+	task.seq = cs.thumbWriteSeq
+	for _, tag := range task.tags {
+		cs.pendingWrites[tag]++
+	}
+}
+
+// finishThumbWrite drops a processed or discarded write from the pending counts. Purge records
+// are only needed while writes for the tag are queued, so they are removed with the last one.
+func (cs *CachedStorage) finishThumbWrite(task cacheWriteTask) {
+	cs.pendingMu.Lock()
+	defer cs.pendingMu.Unlock()
+
+	for _, tag := range task.tags {
+		if cs.pendingWrites[tag]--; cs.pendingWrites[tag] <= 0 {
+			delete(cs.pendingWrites, tag)
+			delete(cs.purgedSeq, tag)
+		}
+	}
+}
+
+// thumbWritePurged reports whether the write was queued before one of its tags was purged
+func (cs *CachedStorage) thumbWritePurged(task cacheWriteTask) bool {
+	cs.pendingMu.Lock()
+	defer cs.pendingMu.Unlock()
+
+	for _, tag := range task.tags {
+		if task.seq <= cs.purgedSeq[tag] {
+			return true
+		}
+	}
+	return false
+}
+
+// markThumbWritesPurged discards thumbnail writes for tag that are queued at this point
+func (cs *CachedStorage) markThumbWritesPurged(tag string) {
+	cs.pendingMu.Lock()
+	defer cs.pendingMu.Unlock()
+
+	if cs.pendingWrites[tag] > 0 {
+		cs.purgedSeq[tag] = cs.thumbWriteSeq
 	}
 }
 
 // SetThumbnailAsync queues an asynchronous write of thumbnail data to disk cache
 // Returns immediately without waiting for write to complete
 // If queue is full, the write is dropped (safe - data is in memory cache anyway)
-func (cs *CachedStorage) SetThumbnailAsync(cacheKey string, data []byte) {
+func (cs *CachedStorage) SetThumbnailAsync(cacheKey string, tags []string, data []byte) {
 	// Make a copy of data since it will be written asynchronously
 	dataCopy := make([]byte, len(data))
 	copy(dataCopy, data)
@@ -314,15 +496,119 @@ func (cs *CachedStorage) SetThumbnailAsync(cacheKey string, data []byte) {
 		return
 	}
 
+	task := cacheWriteTask{key: "thumb:" + cacheKey, tags: tags, data: dataCopy}
+	cs.queueThumbWrite(&task)
+
 	select {
-	case cs.thumbWriteQueue <- cacheWriteTask{key: "thumb:" + cacheKey, data: dataCopy}:
+	case cs.thumbWriteQueue <- task:
 		// Queued successfully
 	default:
 		// Queue full - drop the write (thumbnail is in memory cache already)
+		cs.finishThumbWrite(task)
 		logger.Warnf("[CachedStorage] Thumb write queue full, skipping async write for: %s", cacheKey)
 	}
 }
 
+// Cache types accepted by Flush
+const (
+	CacheTypeSources  = "sources"
+	CacheTypeThumbs   = "thumbs"
+	CacheTypeNegative = "negative"
+	CacheTypeAll      = "all"
+)
+
+// PurgeResult reports how many entries were removed by PurgeSource
+type PurgeResult struct {
+	ThumbsMemory int `json:"thumbs_memory"`
+	ThumbsDisk   int `json:"thumbs_disk"`
+}
+
+// PurgeSource removes a source image and every thumbnail derived from it from all cache layers.
+// Thumbnails with a watermark are also removed when the watermark image is purged.
+// Thumbnails are located through the reverse index built by SetThumbnail/SetThumbnailAsync.
+// Disk writes still queued in the async workers for this source are discarded, so they cannot
+// re-populate the cache afterwards.
+func (cs *CachedStorage) PurgeSource(sourcePath string) PurgeResult {
+	var result PurgeResult
+
+	// Wait for writes in progress and discard the queued ones before deleting
+	cs.purgeMu.Lock()
+	defer cs.purgeMu.Unlock()
+	cs.markThumbWritesPurged(sourcePath)
+
+	sourceKey := "source:" + sourcePath
+	if cs.sourceMemoryCache != nil {
+		cs.sourceMemoryCache.Delete(sourceKey)
+	}
+	if cs.sourceDiskCache != nil {
+		if err := cs.sourceDiskCache.Delete(sourceKey); err != nil {
+			logger.Warnf("[CachedStorage] Error purging source from disk cache: %v", err)
+		}
+	}
+
+	negativeKey := "negative:source:" + sourcePath
+	if cs.negativeMemoryCache != nil {
+		cs.negativeMemoryCache.Delete(negativeKey)
+	}
+	if cs.negativeDiskCache != nil {
+		if err := cs.negativeDiskCache.Delete(negativeKey); err != nil {
+			logger.Warnf("[CachedStorage] Error purging negative entry from disk cache: %v", err)
+		}
+	}
+
+	if cs.thumbMemoryCache != nil {
+		result.ThumbsMemory = cs.thumbMemoryCache.DeleteTag(sourcePath)
+	}
+	if cs.thumbDiskCache != nil {
+		result.ThumbsDisk = cs.thumbDiskCache.DeleteTag(sourcePath)
+	}
+
+	logger.Infof("[CachedStorage] Purged source %s (thumbs: memory=%d, disk=%d)",
+		sourcePath, result.ThumbsMemory, result.ThumbsDisk)
+	return result
+}
+
+// Flush removes all entries of the given cache type from memory and disk layers.
+// cacheType is one of CacheTypeSources, CacheTypeThumbs, CacheTypeNegative or CacheTypeAll.
+func (cs *CachedStorage) Flush(cacheType string) error {
+	var memoryCaches []*cache.MemoryCache
+	var diskCaches []*cache.DiskCache
+
+	switch cacheType {
+	case CacheTypeSources:
+		memoryCaches = append(memoryCaches, cs.sourceMemoryCache)
+		diskCaches = append(diskCaches, cs.sourceDiskCache)
+	case CacheTypeThumbs:
+		memoryCaches = append(memoryCaches, cs.thumbMemoryCache)
+		diskCaches = append(diskCaches, cs.thumbDiskCache)
+	case CacheTypeNegative:
+		memoryCaches = append(memoryCaches, cs.negativeMemoryCache)
+		diskCaches = append(diskCaches, cs.negativeDiskCache)
+	case CacheTypeAll:
+		memoryCaches = append(memoryCaches, cs.sourceMemoryCache, cs.thumbMemoryCache, cs.negativeMemoryCache)
+		diskCaches = append(diskCaches, cs.sourceDiskCache, cs.thumbDiskCache, cs.negativeDiskCache)
+	default:
+		return fmt.Errorf("unknown cache type %q (use %s, %s, %s or %s)",
+			cacheType, CacheTypeSources, CacheTypeThumbs, CacheTypeNegative, CacheTypeAll)
+	}
+
+	for _, mc := range memoryCaches {
+		if mc != nil {
+			mc.Clear()
+		}
+	}
+	for _, dc := range diskCaches {
+		if dc != nil {
+			if err := dc.Clear(); err != nil {
+				return fmt.Errorf("failed to flush %s disk cache: %w", cacheType, err)
+			}
+		}
+	}
+
+	logger.Infof("[CachedStorage] Flushed %s cache", cacheType)
+	return nil
+}
+
 // Ping delegates to underlying storage to check connectivity
 func (cs *CachedStorage) Ping(ctx context.Context) error {
 	return cs.underlying.Ping(ctx)
@@ -351,5 +637,9 @@ func (cs *CachedStorage) Close() error {
 		cs.thumbMemoryCache.Wait()
 		cs.thumbMemoryCache.Close()
 	}
+	if cs.negativeMemoryCache != nil {
+		cs.negativeMemoryCache.Wait()
+		cs.negativeMemoryCache.Close()
+	}
 	return nil
 }
@@ -36,8 +36,9 @@ type StorageConfig struct {
 
 // StorageCacheConfig defines separate cache configurations for sources and thumbnails
 type StorageCacheConfig struct {
-	Sources *CachePair
-	Thumbs  *CachePair
+	Sources  *CachePair
+	Thumbs   *CachePair
+	Negative *CachePair // Short-lived markers for missing and undecodable sources
 }
 
 // CachePair defines separate memory and disk cache configuration for a cache layer
@@ -106,6 +107,9 @@ type CachedStorageConfig struct {
 	ThumbMemoryCache  *MemoryCacheConfig
 	SourceAsyncWrite  *AsyncWriteConfig
 	ThumbAsyncWrite   *AsyncWriteConfig
+
+	NegativeDiskCache   *DiskCacheConfig
+	NegativeMemoryCache *MemoryCacheConfig
 }
 
 // LoadConfig loads storage configuration from environment variables
@@ -145,14 +149,16 @@ func LoadConfig() *StorageConfig {
 func loadCacheConfig() *StorageCacheConfig {
 	sources := loadCachePair("SOURCE")
 	thumbs := loadCachePair("THUMB")
+	negative := loadNegativeCachePair()
 
-	if sources == nil && thumbs == nil {
+	if sources == nil && thumbs == nil && negative == nil {
 		return nil
 	}
 
 	return &StorageCacheConfig{
-		Sources: sources,
-		Thumbs:  thumbs,
+		Sources:  sources,
+		Thumbs:   thumbs,
+		Negative: negative,
 	}
 }
 
@@ -205,6 +211,42 @@ func loadDiskCacheOptions(prefix string) *DiskCacheOptions {
 	}
 }
 
+// loadNegativeCachePair loads the negative cache configuration.
+// Entries are tiny markers, so defaults are much smaller and shorter-lived than for sources.
+// Disk writes are synchronous, so no async write options are exposed.
+func loadNegativeCachePair() *CachePair {
+	var memory *MemoryCacheOptions
+	if getEnvBool("NEGATIVE_MEMORY_CACHE_ENABLED", false) {
+		memory = &MemoryCacheOptions{
+			Enabled:   true,
+			MaxSizeMB: getEnvInt("NEGATIVE_MEMORY_CACHE_MAX_SIZE_MB", 16),
+			MaxItems:  getEnvInt("NEGATIVE_MEMORY_CACHE_MAX_ITEMS", 10000),
+			TTL:       time.Duration(getEnvInt("NEGATIVE_MEMORY_CACHE_TTL_SEC", 30)) * time.Second,
+		}
+	}
+
+	var disk *DiskCacheOptions
+	if getEnvBool("NEGATIVE_DISK_CACHE_ENABLED", false) {
+		disk = &DiskCacheOptions{
+			Enabled:        true,
+			Dir:            getEnv("NEGATIVE_DISK_CACHE_DIR", ""),
+			MaxSizeMB:      getEnvInt("NEGATIVE_DISK_CACHE_MAX_SIZE_MB", 64),
+			MaxItems:       getEnvInt("NEGATIVE_DISK_CACHE_MAX_ITEMS", 100000),
+			TTL:            time.Duration(getEnvInt("NEGATIVE_DISK_CACHE_TTL_SEC", 60)) * time.Second,
+			ClearOnStartup: getEnvBool("NEGATIVE_DISK_CACHE_CLEAR_ON_STARTUP", false),
+		}
+	}
+
+	if memory == nil && disk == nil {
+		return nil
+	}
+
+	return &CachePair{
+		Memory: memory,
+		Disk:   disk,
+	}
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
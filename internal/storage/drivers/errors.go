@@ -0,0 +1,13 @@
+package drivers
+
+import "errors"
+
+// Storage error taxonomy shared by all drivers.
+// Drivers wrap one of these sentinels so callers can classify failures with errors.Is
+// without depending on driver-specific error types.
+var (
+	ErrNotFound    = errors.New("object not found")
+	ErrForbidden   = errors.New("access to object denied")
+	ErrTimeout     = errors.New("storage request timed out")
+	ErrUnavailable = errors.New("storage unavailable")
+)
@@ -35,13 +35,13 @@ func (l *LocalStorage) GetObject(ctx context.Context, key string) ([]byte, error
 	cleanPath := filepath.Clean(key)
 
 	if filepath.IsAbs(cleanPath) || strings.HasPrefix(cleanPath, "..") {
-		return nil, fmt.Errorf("invalid path: absolute paths and parent references not allowed")
+		return nil, fmt.Errorf("%w: invalid path: absolute paths and parent references not allowed", ErrNotFound)
 	}
 
 	fullPath := filepath.Join(l.basePath, cleanPath)
 	absFullPath, err := filepath.Abs(fullPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolve path: %w", err)
+		return nil, fmt.Errorf("%w: failed to resolve path: %w", ErrUnavailable, err)
 	}
 
 	basePathWithSep := l.basePath
@@ -54,7 +54,7 @@ func (l *LocalStorage) GetObject(ctx context.Context, key string) ([]byte, error
 	}
 
 	if !strings.HasPrefix(absFullPathWithSep, basePathWithSep) && absFullPath != l.basePath {
-		return nil, fmt.Errorf("invalid path: directory traversal detected")
+		return nil, fmt.Errorf("%w: invalid path: directory traversal detected", ErrNotFound)
 	}
 
 	// Check if file exists and is accessible
@@ -62,30 +62,30 @@ func (l *LocalStorage) GetObject(ctx context.Context, key string) ([]byte, error
 	if err != nil {
 		if os.IsNotExist(err) {
 			logger.Debugf("[LocalStorage] file not found: %s", absFullPath)
-			return nil, fmt.Errorf("file not found: %s", key)
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
 		}
 		if os.IsPermission(err) {
 			logger.Warnf("[LocalStorage] permission denied: %s", absFullPath)
-			return nil, fmt.Errorf("permission denied for file: %s", key)
+			return nil, fmt.Errorf("%w: permission denied for file: %s", ErrForbidden, key)
 		}
 		logger.Errorf("[LocalStorage] failed to access file %s: %v", absFullPath, err)
-		return nil, fmt.Errorf("failed to access file: %s", key)
+		return nil, fmt.Errorf("%w: failed to access file: %s", ErrUnavailable, key)
 	}
 
 	// Ensure it's a regular file, not a directory
 	if fileInfo.IsDir() {
 		logger.Warnf("[LocalStorage] path is a directory: %s", absFullPath)
-		return nil, fmt.Errorf("path is a directory, not a file: %s", key)
+		return nil, fmt.Errorf("%w: path is a directory, not a file: %s", ErrNotFound, key)
 	}
 
 	data, err := os.ReadFile(absFullPath)
 	if err != nil {
 		if os.IsPermission(err) {
 			logger.Warnf("[LocalStorage] permission denied reading file: %s", absFullPath)
-			return nil, fmt.Errorf("permission denied reading file: %s", key)
+			return nil, fmt.Errorf("%w: permission denied reading file: %s", ErrForbidden, key)
 		}
 		logger.Errorf("[LocalStorage] failed to read file %s: %v", absFullPath, err)
-		return nil, fmt.Errorf("failed to read file: %s", key)
+		return nil, fmt.Errorf("%w: failed to read file: %s", ErrUnavailable, key)
 	}
 
 	return data, nil
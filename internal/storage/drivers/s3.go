@@ -3,6 +3,8 @@ package drivers
 import (
 	"context"
 	"crypto/tls"
+	"errors"
+	"fmt"
 	"io"
 	"net"
 	"net/http"
@@ -158,7 +160,12 @@ func (s *S3Client) GetObject(ctx context.Context, key string) ([]byte, error) {
 		Key:    aws.String(key),
 	})
 	if err != nil {
-		logger.Errorf("[S3 Storage] Error fetching object: bucket=%s, key=%s, error=%v", s.bucket, key, err)
+		err = classifyS3Error(key, err)
+		if errors.Is(err, ErrNotFound) {
+			logger.Debugf("[S3 Storage] Object not found: bucket=%s, key=%s", s.bucket, key)
+		} else {
+			logger.Errorf("[S3 Storage] Error fetching object: bucket=%s, key=%s, error=%v", s.bucket, key, err)
+		}
 		return nil, err
 	}
 	defer result.Body.Close()
@@ -166,7 +173,7 @@ func (s *S3Client) GetObject(ctx context.Context, key string) ([]byte, error) {
 	data, err := io.ReadAll(result.Body)
 	if err != nil {
 		logger.Errorf("[S3 Storage] Error reading object body: bucket=%s, key=%s, error=%v", s.bucket, key, err)
-		return nil, err
+		return nil, classifyS3Error(key, err)
 	}
 	logger.Debugf("[S3 Storage] Successfully fetched object: bucket=%s, key=%s, size=%d bytes", s.bucket, key, len(data))
 	return data, nil
@@ -179,3 +186,35 @@ func (s *S3Client) Ping(ctx context.Context) error {
 	})
 	return err
 }
+
+// httpStatusError is implemented by SDK response errors that carry an HTTP status code.
+type httpStatusError interface {
+	HTTPStatusCode() int
+}
+
+// classifyS3Error wraps an S3 SDK error with the matching storage error sentinel.
+// The original error is kept in the chain for logging.
+func classifyS3Error(key string, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %s: %w", ErrTimeout, key, err)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return fmt.Errorf("%w: %s: %w", ErrTimeout, key, err)
+	}
+
+	var statusErr httpStatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.HTTPStatusCode() {
+		case http.StatusNotFound:
+			return fmt.Errorf("%w: %s", ErrNotFound, key)
+		case http.StatusForbidden, http.StatusUnauthorized:
+			return fmt.Errorf("%w: %s: %w", ErrForbidden, key, err)
+		case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+			return fmt.Errorf("%w: %s: %w", ErrTimeout, key, err)
+		}
+	}
+
+	return fmt.Errorf("%w: %s: %w", ErrUnavailable, key, err)
+}
@@ -77,7 +77,11 @@ func wrapWithCache(baseStorage drivers.Storage, cfg *StorageConfig) (drivers.Sto
 		((cfg.Cache.Thumbs.Disk != nil && cfg.Cache.Thumbs.Disk.Enabled) ||
 			(cfg.Cache.Thumbs.Memory != nil && cfg.Cache.Thumbs.Memory.Enabled))
 
-	if !sourcesEnabled && !thumbsEnabled {
+	negativeEnabled := cfg.Cache.Negative != nil &&
+		((cfg.Cache.Negative.Disk != nil && cfg.Cache.Negative.Disk.Enabled) ||
+			(cfg.Cache.Negative.Memory != nil && cfg.Cache.Negative.Memory.Enabled))
+
+	if !sourcesEnabled && !thumbsEnabled && !negativeEnabled {
 		logger.Infof("[Cache] No cache enabled")
 		return baseStorage, nil
 	}
@@ -90,6 +94,9 @@ func wrapWithCache(baseStorage drivers.Storage, cfg *StorageConfig) (drivers.Sto
 	if thumbsEnabled {
 		cacheInfo = append(cacheInfo, "Thumbs")
 	}
+	if negativeEnabled {
+		cacheInfo = append(cacheInfo, "Negative")
+	}
 	logger.Infof("[Cache] Enabled for: %s", strings.Join(cacheInfo, ", "))
 
 	cacheConfig := CachedStorageConfig{}
@@ -229,6 +236,46 @@ func wrapWithCache(baseStorage drivers.Storage, cfg *StorageConfig) (drivers.Sto
 		}
 	}
 
+	// Configure negative caches
+	if negativeEnabled {
+		negativeCfg := cfg.Cache.Negative
+		negativeTTL := 30 * time.Second
+
+		if negativeCfg.Memory != nil && negativeCfg.Memory.Enabled {
+			memTTL := negativeTTL
+			if negativeCfg.Memory.TTL > 0 {
+				memTTL = negativeCfg.Memory.TTL
+			}
+			cacheConfig.NegativeMemoryCache = &MemoryCacheConfig{
+				Enabled:   true,
+				MaxSizeMB: negativeCfg.Memory.MaxSizeMB,
+				MaxItems:  negativeCfg.Memory.MaxItems,
+				TTL:       memTTL,
+			}
+		}
+
+		if negativeCfg.Disk != nil && negativeCfg.Disk.Enabled {
+			if negativeCfg.Disk.Dir == "" {
+				return nil, fmt.Errorf("NEGATIVE_DISK_CACHE_DIR is required when disk cache is enabled")
+			}
+			if err := os.MkdirAll(negativeCfg.Disk.Dir, 0755); err != nil {
+				return nil, fmt.Errorf("failed to create negative cache directory: %w", err)
+			}
+			diskTTL := negativeTTL
+			if negativeCfg.Disk.TTL > 0 {
+				diskTTL = negativeCfg.Disk.TTL
+			}
+			cacheConfig.NegativeDiskCache = &DiskCacheConfig{
+				Enabled:        true,
+				BasePath:       negativeCfg.Disk.Dir,
+				TTL:            diskTTL,
+				ClearOnStartup: negativeCfg.Disk.ClearOnStartup,
+				MaxSizeMB:      negativeCfg.Disk.MaxSizeMB,
+				MaxItems:       negativeCfg.Disk.MaxItems,
+			}
+		}
+	}
+
 	return newCachedStorage(baseStorage, cacheConfig)
 }
 
@@ -241,8 +288,11 @@ func newCachedStorage(underlying drivers.Storage, cfg CachedStorageConfig) (*Cac
 	thumbsCacheEnabled := (cfg.ThumbMemoryCache != nil && cfg.ThumbMemoryCache.Enabled) ||
 		(cfg.ThumbDiskCache != nil && cfg.ThumbDiskCache.Enabled)
 
-	if !sourcesCacheEnabled && !thumbsCacheEnabled {
-		return nil, fmt.Errorf("at least one cache (sources or thumbs) must be enabled")
+	negativeCacheEnabled := (cfg.NegativeMemoryCache != nil && cfg.NegativeMemoryCache.Enabled) ||
+		(cfg.NegativeDiskCache != nil && cfg.NegativeDiskCache.Enabled)
+
+	if !sourcesCacheEnabled && !thumbsCacheEnabled && !negativeCacheEnabled {
+		return nil, fmt.Errorf("at least one cache (sources, thumbs or negative) must be enabled")
 	}
 
 	cs := &CachedStorage{
@@ -266,6 +316,14 @@ func newCachedStorage(underlying drivers.Storage, cfg CachedStorageConfig) (*Cac
 	}
 	cs.thumbTTL = thumbTTL
 
+	negativeTTL := 30 * time.Second
+	if cfg.NegativeMemoryCache != nil && cfg.NegativeMemoryCache.TTL > 0 {
+		negativeTTL = cfg.NegativeMemoryCache.TTL
+	} else if cfg.NegativeDiskCache != nil && cfg.NegativeDiskCache.TTL > 0 {
+		negativeTTL = cfg.NegativeDiskCache.TTL
+	}
+	cs.negativeTTL = negativeTTL
+
 	// Initialize source caches
 	if sourcesCacheEnabled {
 		if cfg.SourceMemoryCache != nil && cfg.SourceMemoryCache.Enabled {
@@ -352,6 +410,44 @@ func newCachedStorage(underlying drivers.Storage, cfg CachedStorageConfig) (*Cac
 		}
 	}
 
+	// Initialize negative caches
+	if negativeCacheEnabled {
+		if cfg.NegativeMemoryCache != nil && cfg.NegativeMemoryCache.Enabled {
+			memCache, err := cache.NewMemoryCache(cache.MemoryCacheConfig{
+				MaxSize:  int64(cfg.NegativeMemoryCache.MaxSizeMB) * 1024 * 1024,
+				MaxItems: int64(cfg.NegativeMemoryCache.MaxItems),
+				TTL:      cfg.NegativeMemoryCache.TTL,
+			})
+			if err != nil {
+				logger.Warnf("[CachedStorage] Failed to init negative memory cache: %v", err)
+			} else {
+				cs.negativeMemoryCache = memCache
+				logger.Infof("[CachedStorage] Negative memory cache: MaxSize=%dMB, MaxItems=%d, TTL=%v",
+					cfg.NegativeMemoryCache.MaxSizeMB, cfg.NegativeMemoryCache.MaxItems, cfg.NegativeMemoryCache.TTL)
+			}
+		}
+
+		if cfg.NegativeDiskCache != nil && cfg.NegativeDiskCache.Enabled {
+			diskCacheMaxBytes := int64(0)
+			if cfg.NegativeDiskCache.MaxSizeMB > 0 {
+				diskCacheMaxBytes = int64(cfg.NegativeDiskCache.MaxSizeMB) * 1024 * 1024
+			}
+			diskCache, err := cache.NewDiskCache(
+				cfg.NegativeDiskCache.BasePath,
+				cfg.NegativeDiskCache.TTL,
+				cfg.NegativeDiskCache.ClearOnStartup,
+				diskCacheMaxBytes,
+				cfg.NegativeDiskCache.MaxItems,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create negative disk cache: %w", err)
+			}
+			cs.negativeDiskCache = diskCache
+			logger.Infof("[CachedStorage] Negative disk cache: Dir=%s, MaxSize=%dMB, TTL=%v",
+				cfg.NegativeDiskCache.BasePath, cfg.NegativeDiskCache.MaxSizeMB, cfg.NegativeDiskCache.TTL)
+		}
+	}
+
 	// Initialize async write workers for sources and thumbs
 	if cfg.SourceAsyncWrite != nil && cfg.SourceAsyncWrite.Enabled && cs.sourceDiskCache != nil {
 		cs.initSourceWorkers(cfg.SourceAsyncWrite.NumWorkers, cfg.SourceAsyncWrite.QueueSize)
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"lukechampine.com/blake3"
+)
+
+// computeETag returns a strong, quoted entity tag for thumbnail bytes.
+// A truncated BLAKE3 digest is plenty to distinguish variants of the same URL.
+func computeETag(data []byte) string {
+	hash := blake3.Sum256(data)
+	return `"` + hex.EncodeToString(hash[:16]) + `"`
+}
+
+// notModified evaluates the request's conditional headers against the thumbnail validators.
+// If-None-Match takes precedence over If-Modified-Since, as required by RFC 9110.
+// Only GET and HEAD requests can be answered with 304.
+func notModified(r *http.Request, thumbnail *ThumbnailResult) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagListMatches(inm, thumbnail.ETag)
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || thumbnail.LastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	return !thumbnail.LastModified.Truncate(time.Second).After(since)
+}
+
+// etagListMatches reports whether any entity tag in an If-None-Match header value
+// matches etag using weak comparison (the W/ prefix is ignored).
+func etagListMatches(header, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+
+	for candidate := range strings.SplitSeq(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -53,6 +53,9 @@ func formatOperation(op operations.Operation, hasFit, hasExplicitQuality bool, d
 	case *operations.ResizeOperation:
 		return formatResizeOperation(v, hasFit, hasExplicitQuality, defaultQuality), true
 	case *operations.FormatOperation:
+		if v.Auto {
+			return fmt.Sprintf("format(auto → %s)", v.Format), true
+		}
 		return fmt.Sprintf("format(%s)", v.Format), true
 	case *operations.QualityOperation:
 		if hasExplicitQuality {
@@ -65,6 +68,58 @@ func formatOperation(op operations.Operation, hasFit, hasExplicitQuality bool, d
 		return fmt.Sprintf("pcrop(%d,%d,%d,%d)", v.X1, v.Y1, v.X2, v.Y2), true
 	case *operations.FitOperation:
 		return formatFitOperation(v), true
+	case *operations.GravityOperation:
+		return fmt.Sprintf("gravity(%s)", v.Gravity), true
+	case *operations.FocalOperation:
+		return fmt.Sprintf("focal(%d,%d)", v.X, v.Y), true
+	case *operations.PercentFocalOperation:
+		return fmt.Sprintf("pfocal(%g,%g)", v.X, v.Y), true
+	case *operations.RotateOperation:
+		return fmt.Sprintf("rotate(%g, %s)", v.Angle, v.Background), true
+	case *operations.FlipOperation:
+		return fmt.Sprintf("flip(%s)", v.Direction), true
+	case *operations.BlurOperation:
+		return fmt.Sprintf("blur(%g)", v.Sigma), true
+	case *operations.SharpenOperation:
+		return fmt.Sprintf("sharpen(%g,%g,%g)", v.Sigma, v.X1, v.M2), true
+	case *operations.DPROperation:
+		return fmt.Sprintf("dpr(%g)", v.Ratio), true
+	case *operations.WatermarkOperation:
+		return fmt.Sprintf("watermark(%s, %s, %g, %g)", v.Path, v.Position, v.Opacity, v.Scale), true
+	case *operations.BrightnessOperation:
+		return fmt.Sprintf("brightness(%g)", v.Brightness), true
+	case *operations.ContrastOperation:
+		return fmt.Sprintf("contrast(%g)", v.Contrast), true
+	case *operations.SaturationOperation:
+		return fmt.Sprintf("saturation(%g)", v.Saturation), true
+	case *operations.GammaOperation:
+		return fmt.Sprintf("gamma(%g)", v.Gamma), true
+	case *operations.TintOperation:
+		return fmt.Sprintf("tint(%s)", v.Color), true
+	case *operations.RadiusOperation:
+		if v.Percent {
+			return fmt.Sprintf("radius(%g%%)", v.Radius), true
+		}
+		return fmt.Sprintf("radius(%g)", v.Radius), true
+	case *operations.BorderOperation:
+		return fmt.Sprintf("border(%d, %s)", v.Width, v.Color), true
+	case *operations.TrimOperation:
+		return fmt.Sprintf("trim(%g)", v.Threshold), true
+	case *operations.MetadataOperation:
+		if len(v.Keep) == 0 {
+			return "strip()", true
+		}
+		return fmt.Sprintf("keep(%s)", strings.Join(v.Keep, ",")), true
+	case *operations.EncoderToggleOperation:
+		return fmt.Sprintf("%s(%t)", v.Name(), v.Enabled), true
+	case *operations.SubsamplingOperation:
+		return fmt.Sprintf("subsampling(%s)", v.Subsampling), true
+	case *operations.EffortOperation:
+		return fmt.Sprintf("effort(%d)", v.Effort), true
+	case *operations.MaxBytesOperation:
+		return fmt.Sprintf("maxbytes(%d, %d)", v.Bytes, v.MinQuality), true
+	case *operations.TextOperation:
+		return fmt.Sprintf("text(%q, %s, %d, %s, %s, %s)", v.Text, v.Position, v.Size, v.Color, v.Background, v.Font), true
 	default:
 		return op.Name(), true
 	}
@@ -90,8 +145,12 @@ func formatResizeOperation(v *operations.ResizeOperation, hasFit, hasExplicitQua
 }
 
 func formatFitOperation(v *operations.FitOperation) string {
-	if v.FillColor != "" && v.Mode == "fill" {
-		return fmt.Sprintf("fit(%s, %s)", v.Mode, v.FillColor)
+	args := v.Mode
+	if v.Mode == operations.FitFill {
+		args += fmt.Sprintf(", %s", v.FillColor)
+	}
+	if v.NoUpscale {
+		args += ", noupscale"
 	}
-	return fmt.Sprintf("fit(%s)", v.Mode)
+	return fmt.Sprintf("fit(%s)", args)
 }
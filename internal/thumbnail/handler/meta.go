@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/sashko-guz/mage/internal/imaging/operations"
+	"github.com/sashko-guz/mage/internal/pkg/logger"
+	storageDrivers "github.com/sashko-guz/mage/internal/storage/drivers"
+)
+
+// ServeMeta handles /meta/ requests. It accepts the same path syntax as thumbnail requests
+// and returns JSON describing the source image and the output dimensions, without encoding
+// the thumbnail. Responses are not cached.
+func (h *ThumbnailHandler) ServeMeta(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.parseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if negotiateFormat(r, req) != "" {
+		// The reported output format depends on the Accept header
+		w.Header().Add("Vary", "Accept")
+	}
+
+	if !h.validateSignature(w, r, req) {
+		return
+	}
+
+	info, err := h.describe(r, req)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", h.cfg.CacheControlResponseHeader)
+	if err := json.NewEncoder(w).Encode(info); err != nil {
+		logger.Warnf("[ThumbnailHandler] Error writing meta response: %v", err)
+	}
+}
+
+// describe fetches the source image and reports its properties under the processing
+// concurrency limit, since output dimensions are computed by running the operations.
+func (h *ThumbnailHandler) describe(r *http.Request, req *operations.Request) (*operations.ImageInfo, error) {
+	imageData, err := h.storage.GetObject(r.Context(), req.Path)
+	if err != nil {
+		if errors.Is(err, storageDrivers.ErrNotFound) {
+			logger.Debugf("[ThumbnailHandler] Source image not found: %s", req.Path)
+		} else {
+			logger.Errorf("[ThumbnailHandler] Error fetching image from storage: %v", err)
+		}
+		return nil, err
+	}
+
+	if h.cfg.MaxInputSize > 0 && len(imageData) > h.cfg.MaxInputSize {
+		logger.Warnf("[ThumbnailHandler] Source image too large: path=%s, size=%d bytes, limit=%d bytes",
+			req.Path, len(imageData), h.cfg.MaxInputSize)
+		return nil, &inputImageTooLargeError{Actual: len(imageData), Limit: h.cfg.MaxInputSize}
+	}
+
+	h.processSem <- struct{}{}
+	defer func() { <-h.processSem }()
+
+	info, err := h.processor.Describe(r.Context(), imageData, req)
+	if err != nil {
+		if errors.Is(err, operations.ErrUndecodableImage) || errors.Is(err, operations.ErrAnimationTooLarge) {
+			logger.Warnf("[ThumbnailHandler] Cannot describe source image: path=%s, error=%v", req.Path, err)
+		} else {
+			logger.Errorf("[ThumbnailHandler] Error describing image: %v", err)
+		}
+		return nil, err
+	}
+
+	return info, nil
+}
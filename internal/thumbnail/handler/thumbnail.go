@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"net/http"
@@ -25,18 +26,21 @@ type MetricsRecorder interface {
 }
 
 type ThumbnailResult struct {
-	Data        []byte
-	ContentType string
+	Data         []byte
+	ContentType  string
+	ETag         string    // Strong entity tag (quoted) derived from Data
+	LastModified time.Time // Generation time, zero for legacy cache entries
+	Quality      int       // Quality chosen by maxbytes(...), zero when not searched
 }
 
 type ThumbnailHandler struct {
-	storage        storageDrivers.Storage
-	processor      *processor.ImageProcessor
-	singleflight   *singleflight.Group
-	processSem     chan struct{}
-	signer  *signature.Signature // URL signature handler
-	cfg     ThumbnailHandlerConfig
-	metrics MetricsRecorder
+	storage      storageDrivers.Storage
+	processor    *processor.ImageProcessor
+	singleflight *singleflight.Group
+	processSem   chan struct{}
+	signer       *signature.Signature // URL signature handler
+	cfg          ThumbnailHandlerConfig
+	metrics      MetricsRecorder
 }
 
 // ThumbnailHandlerConfig holds configuration for the thumbnail handler.
@@ -104,14 +108,23 @@ func buildSigner(cfg signature.Config) (*signature.Signature, error) {
 // -------------------------------------------------------------------
 
 func (h *ThumbnailHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	cacheKey := r.URL.Path
-
-	if h.serveCachedThumbnail(w, cacheKey) {
+	req, ok := h.parseRequest(w, r)
+	if !ok {
 		return
 	}
 
-	req, ok := h.parseRequest(w, r)
-	if !ok {
+	cacheKey := r.URL.Path
+	if req.PresetHash != "" {
+		// Presets are referenced by name, so the key must change when the definition does
+		cacheKey += "|preset=" + req.PresetHash
+	}
+	if variant := negotiateFormat(r, req); variant != "" {
+		// Negotiated output depends on the Accept header, so it must be cached per variant
+		w.Header().Add("Vary", "Accept")
+		cacheKey += "|format=" + variant
+	}
+
+	if h.serveCachedThumbnail(w, r, cacheKey) {
 		return
 	}
 
@@ -123,7 +136,8 @@ func (h *ThumbnailHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	result, isDuplicate, err := h.processWithSingleflight(r, req, cacheKey)
 
-	binaryData := h.cacheResult(cacheKey, result, err)
+	tags := cacheTags(req)
+	binaryData := h.cacheResult(cacheKey, tags, result, err)
 
 	if err != nil {
 		h.writeError(w, r, err)
@@ -136,14 +150,14 @@ func (h *ThumbnailHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		logger.Debugf("[ThumbnailHandler] Concurrent duplicate request served from singleflight: %s", cacheKey)
 	}
 
-	h.writeThumbnailResponse(w, thumbnail, "MISS")
+	h.writeThumbnailResponse(w, r, thumbnail, "MISS")
 	logger.Debugf("[ThumbnailHandler] Successfully generated thumbnail for: %s", req.Path)
-	h.scheduleAsyncCacheWrite(cacheKey, binaryData)
+	h.scheduleAsyncCacheWrite(cacheKey, tags, binaryData)
 }
 
 // serveCachedThumbnail checks the thumbnail cache and writes the response if a cached entry is
 // found. Returns true when the response has been served and no further processing is needed.
-func (h *ThumbnailHandler) serveCachedThumbnail(w http.ResponseWriter, cacheKey string) bool {
+func (h *ThumbnailHandler) serveCachedThumbnail(w http.ResponseWriter, r *http.Request, cacheKey string) bool {
 	if !h.cfg.CachingEnabled {
 		return false
 	}
@@ -165,10 +179,22 @@ func (h *ThumbnailHandler) serveCachedThumbnail(w http.ResponseWriter, cacheKey
 	}
 
 	logger.Debugf("[ThumbnailHandler] Cache HIT - serving thumbnail immediately: %s", cacheKey)
-	h.writeThumbnailResponse(w, thumbnail, "HIT")
+	h.writeThumbnailResponse(w, r, thumbnail, "HIT")
 	return true
 }
 
+// negotiateFormat resolves format(auto) from the request Accept header.
+// Returns the negotiated variant ("avif", "webp" or "auto" for the JPEG/PNG fallback),
+// or "" when the request uses a fixed output format.
+func negotiateFormat(r *http.Request, req *operations.Request) string {
+	for _, op := range req.Operations {
+		if formatOp, ok := op.(*operations.FormatOperation); ok {
+			return formatOp.Negotiate(r.Header.Get("Accept"))
+		}
+	}
+	return ""
+}
+
 // parseRequest parses the URL path and enforces signature-presence rules.
 // Writes an appropriate error response and returns false on failure.
 func (h *ThumbnailHandler) parseRequest(w http.ResponseWriter, r *http.Request) (*operations.Request, bool) {
@@ -239,7 +265,11 @@ func (h *ThumbnailHandler) processWithSingleflight(r *http.Request, req *operati
 func (h *ThumbnailHandler) fetchAndProcess(r *http.Request, req *operations.Request) (*ThumbnailResult, error) {
 	imageData, err := h.storage.GetObject(r.Context(), req.Path)
 	if err != nil {
-		logger.Errorf("[ThumbnailHandler] Error fetching image from storage: %v", err)
+		if errors.Is(err, storageDrivers.ErrNotFound) {
+			logger.Debugf("[ThumbnailHandler] Source image not found: %s", req.Path)
+		} else {
+			logger.Errorf("[ThumbnailHandler] Error fetching image from storage: %v", err)
+		}
 		return nil, err
 	}
 
@@ -250,9 +280,20 @@ func (h *ThumbnailHandler) fetchAndProcess(r *http.Request, req *operations.Requ
 	}
 
 	start := time.Now()
-	thumbnail, contentType, err := h.processor.CreateThumbnail(imageData, req)
+	thumbnail, contentType, err := h.processor.CreateThumbnail(r.Context(), imageData, req)
 	if err != nil {
-		logger.Errorf("[ThumbnailHandler] Error creating thumbnail: %v", err)
+		if errors.Is(err, operations.ErrUndecodableImage) {
+			logger.Warnf("[ThumbnailHandler] Source image cannot be decoded: path=%s, error=%v", req.Path, err)
+			if cachedStore, ok := h.storage.(*storage.CachedStorage); ok {
+				cachedStore.SetSourceUndecodable(req.Path)
+			}
+		} else if errors.Is(err, operations.ErrAnimationTooLarge) {
+			logger.Warnf("[ThumbnailHandler] Source animation too large: path=%s, error=%v", req.Path, err)
+		} else if errors.Is(err, operations.ErrResourceUnavailable) {
+			logger.Warnf("[ThumbnailHandler] Overlay resource unavailable: path=%s, error=%v", req.Path, err)
+		} else {
+			logger.Errorf("[ThumbnailHandler] Error creating thumbnail: %v", err)
+		}
 		return nil, err
 	}
 
@@ -262,7 +303,35 @@ func (h *ThumbnailHandler) fetchAndProcess(r *http.Request, req *operations.Requ
 		h.metrics.RecordImageProcessing(format, time.Since(start).Seconds())
 	}
 
-	return &ThumbnailResult{Data: thumbnail, ContentType: contentType}, nil
+	return &ThumbnailResult{
+		Data:         thumbnail,
+		ContentType:  contentType,
+		ETag:         computeETag(thumbnail),
+		LastModified: time.Now().UTC().Truncate(time.Second),
+		Quality:      chosenQuality(req),
+	}, nil
+}
+
+// chosenQuality returns the quality picked by maxbytes(...), or zero without a byte budget
+func chosenQuality(req *operations.Request) int {
+	for _, op := range req.Operations {
+		if maxBytesOp, ok := op.(*operations.MaxBytesOperation); ok {
+			return maxBytesOp.Quality
+		}
+	}
+	return 0
+}
+
+// cacheTags returns the storage keys the thumbnail is generated from: the source and any
+// watermark images. Purging any of them removes the cached thumbnail.
+func cacheTags(req *operations.Request) []string {
+	tags := []string{req.Path}
+	for _, op := range req.Operations {
+		if watermarkOp, ok := op.(*operations.WatermarkOperation); ok {
+			tags = append(tags, watermarkOp.Path)
+		}
+	}
+	return tags
 }
 
 // getOutputFormat extracts the output format from the request operations
@@ -277,7 +346,8 @@ func (h *ThumbnailHandler) getOutputFormat(req *operations.Request) string {
 
 // cacheResult stores the thumbnail in the synchronous (memory) cache and returns the encoded
 // binary data so the caller can schedule an async disk write afterwards.
-func (h *ThumbnailHandler) cacheResult(cacheKey string, result any, err error) []byte {
+// tags are recorded so the thumbnail can be purged together with its source or watermark.
+func (h *ThumbnailHandler) cacheResult(cacheKey string, tags []string, result any, err error) []byte {
 	if !h.cfg.CachingEnabled || err != nil || result == nil {
 		return nil
 	}
@@ -288,7 +358,7 @@ func (h *ThumbnailHandler) cacheResult(cacheKey string, result any, err error) [
 	}
 
 	binaryData := encodeThumbnailBinary(result.(*ThumbnailResult))
-	if cacheErr := cachedStore.SetThumbnail(cacheKey, binaryData); cacheErr != nil {
+	if cacheErr := cachedStore.SetThumbnail(cacheKey, tags, binaryData); cacheErr != nil {
 		logger.Warnf("[ThumbnailHandler] Error caching thumbnail result: %v", cacheErr)
 	}
 
@@ -308,18 +378,72 @@ func (h *ThumbnailHandler) writeError(w http.ResponseWriter, r *http.Request, er
 		return
 	}
 
+	if errors.Is(err, operations.ErrUndecodableImage) || errors.Is(err, storage.ErrSourceUndecodable) {
+		http.Error(w, "Source image cannot be decoded", http.StatusUnprocessableEntity)
+		return
+	}
+
+	if errors.Is(err, operations.ErrAnimationTooLarge) {
+		http.Error(w, fmt.Sprintf("Source %v", err), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	// Checked before storage errors: a missing watermark must not look like a missing source
+	if errors.Is(err, operations.ErrResourceUnavailable) {
+		http.Error(w, "Overlay resource is unavailable", http.StatusBadGateway)
+		return
+	}
+
+	if status, ok := storageErrorStatus(err); ok {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
 	http.Error(w,
 		fmt.Sprintf("Failed to create thumbnail: %v (url=%s)", err, r.URL.String()),
 		http.StatusInternalServerError,
 	)
 }
 
-// writeThumbnailResponse sends the thumbnail bytes with standard caching headers.
+// storageErrorStatus maps storage driver errors to HTTP status codes.
+// Missing sources become 404 so CDNs and alerting do not treat user typos as server failures.
+func storageErrorStatus(err error) (int, bool) {
+	switch {
+	case errors.Is(err, storageDrivers.ErrNotFound):
+		return http.StatusNotFound, true
+	case errors.Is(err, storageDrivers.ErrForbidden):
+		return http.StatusForbidden, true
+	case errors.Is(err, storageDrivers.ErrTimeout):
+		return http.StatusGatewayTimeout, true
+	case errors.Is(err, storageDrivers.ErrUnavailable):
+		return http.StatusBadGateway, true
+	default:
+		return 0, false
+	}
+}
+
+// writeThumbnailResponse sends the thumbnail bytes with standard caching headers,
+// or 304 Not Modified when the request's validators match the thumbnail.
 // cacheStatus is used as the X-Mage-Cache header value ("HIT" or "MISS").
-func (h *ThumbnailHandler) writeThumbnailResponse(w http.ResponseWriter, thumbnail *ThumbnailResult, cacheStatus string) {
-	w.Header().Set("Content-Type", thumbnail.ContentType)
+func (h *ThumbnailHandler) writeThumbnailResponse(w http.ResponseWriter, r *http.Request, thumbnail *ThumbnailResult, cacheStatus string) {
 	w.Header().Set("Cache-Control", h.cfg.CacheControlResponseHeader)
 	w.Header().Set("X-Mage-Cache", cacheStatus)
+	if thumbnail.ETag != "" {
+		w.Header().Set("ETag", thumbnail.ETag)
+	}
+	if !thumbnail.LastModified.IsZero() {
+		w.Header().Set("Last-Modified", thumbnail.LastModified.Format(http.TimeFormat))
+	}
+	if thumbnail.Quality > 0 {
+		w.Header().Set("X-Mage-Quality", strconv.Itoa(thumbnail.Quality))
+	}
+
+	if notModified(r, thumbnail) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", thumbnail.ContentType)
 	w.Header().Set("Content-Length", strconv.Itoa(len(thumbnail.Data)))
 
 	if _, err := w.Write(thumbnail.Data); err != nil {
@@ -328,49 +452,140 @@ func (h *ThumbnailHandler) writeThumbnailResponse(w http.ResponseWriter, thumbna
 }
 
 // scheduleAsyncCacheWrite queues a background disk-cache write after the response is sent.
-func (h *ThumbnailHandler) scheduleAsyncCacheWrite(cacheKey string, binaryData []byte) {
+func (h *ThumbnailHandler) scheduleAsyncCacheWrite(cacheKey string, tags []string, binaryData []byte) {
 	if binaryData == nil {
 		return
 	}
 	cachedStore := h.storage.(*storage.CachedStorage)
-	cachedStore.SetThumbnailAsync(cacheKey, binaryData)
+	cachedStore.SetThumbnailAsync(cacheKey, tags, binaryData)
 }
 
 // -------------------------------------------------------------------
 // Binary encoding helpers
 // -------------------------------------------------------------------
 
+// thumbnailEnvelopeMagic prefixes the current binary format. The legacy format starts with a
+// 4-byte big-endian content-type length whose first byte is always zero, so the two never collide.
+var thumbnailEnvelopeMagic = [4]byte{'M', 'G', 'T', 1}
+
+// Envelope field names, modelled after the response headers they populate
+const (
+	envelopeContentType  = "Content-Type"
+	envelopeETag         = "ETag"
+	envelopeLastModified = "Last-Modified"
+	envelopeQuality      = "X-Mage-Quality"
+)
+
 // encodeThumbnailBinary encodes a ThumbnailResult to a compact binary format.
-// Layout: [4 bytes: content-type length (big-endian)][content-type bytes][image data]
+// Layout: [4 bytes: magic][2 bytes: field count]
+// then per field: [2 bytes: name length][name][4 bytes: value length][value]
+// followed by the image data. All integers are big-endian.
 func encodeThumbnailBinary(t *ThumbnailResult) []byte {
-	ctLen := len(t.ContentType)
-	out := make([]byte, 4+ctLen+len(t.Data))
+	fields := [][2]string{
+		{envelopeContentType, t.ContentType},
+		{envelopeETag, t.ETag},
+	}
+	if !t.LastModified.IsZero() {
+		fields = append(fields, [2]string{envelopeLastModified, strconv.FormatInt(t.LastModified.Unix(), 10)})
+	}
+	if t.Quality > 0 {
+		fields = append(fields, [2]string{envelopeQuality, strconv.Itoa(t.Quality)})
+	}
 
-	out[0] = byte(ctLen >> 24)
-	out[1] = byte(ctLen >> 16)
-	out[2] = byte(ctLen >> 8)
-	out[3] = byte(ctLen)
+	size := len(thumbnailEnvelopeMagic) + 2 + len(t.Data)
+	for _, f := range fields {
+		size += 2 + len(f[0]) + 4 + len(f[1])
+	}
 
-	copy(out[4:], t.ContentType)
-	copy(out[4+ctLen:], t.Data)
+	out := make([]byte, 0, size)
+	out = append(out, thumbnailEnvelopeMagic[:]...)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(fields)))
+	for _, f := range fields {
+		out = binary.BigEndian.AppendUint16(out, uint16(len(f[0])))
+		out = append(out, f[0]...)
+		out = binary.BigEndian.AppendUint32(out, uint32(len(f[1])))
+		out = append(out, f[1]...)
+	}
+	out = append(out, t.Data...)
 
 	return out
 }
 
 // decodeThumbnailBinary decodes a binary-encoded thumbnail back to a ThumbnailResult.
+// Entries written in the legacy format get their ETag computed on the fly.
 func decodeThumbnailBinary(data []byte) (*ThumbnailResult, error) {
 	if len(data) < 4 {
 		return nil, fmt.Errorf("invalid binary thumbnail format: too short")
 	}
 
-	ctLen := uint32(data[0])<<24 | uint32(data[1])<<16 | uint32(data[2])<<8 | uint32(data[3])
+	if [4]byte(data[:4]) != thumbnailEnvelopeMagic {
+		return decodeLegacyThumbnailBinary(data)
+	}
+
+	pos := len(thumbnailEnvelopeMagic)
+	if len(data) < pos+2 {
+		return nil, fmt.Errorf("invalid binary thumbnail format: field count truncated")
+	}
+	count := int(binary.BigEndian.Uint16(data[pos:]))
+	pos += 2
+
+	t := &ThumbnailResult{}
+	for range count {
+		if len(data) < pos+2 {
+			return nil, fmt.Errorf("invalid binary thumbnail format: field name truncated")
+		}
+		nameLen := int(binary.BigEndian.Uint16(data[pos:]))
+		pos += 2
+		if len(data) < pos+nameLen+4 {
+			return nil, fmt.Errorf("invalid binary thumbnail format: field name truncated")
+		}
+		name := string(data[pos : pos+nameLen])
+		pos += nameLen
+
+		valueLen := int(binary.BigEndian.Uint32(data[pos:]))
+		pos += 4
+		if len(data) < pos+valueLen {
+			return nil, fmt.Errorf("invalid binary thumbnail format: field %s truncated", name)
+		}
+		value := string(data[pos : pos+valueLen])
+		pos += valueLen
+
+		switch name {
+		case envelopeContentType:
+			t.ContentType = value
+		case envelopeETag:
+			t.ETag = value
+		case envelopeLastModified:
+			if sec, err := strconv.ParseInt(value, 10, 64); err == nil {
+				t.LastModified = time.Unix(sec, 0).UTC()
+			}
+		case envelopeQuality:
+			if quality, err := strconv.Atoi(value); err == nil {
+				t.Quality = quality
+			}
+		}
+	}
+
+	t.Data = data[pos:]
+	if t.ETag == "" {
+		t.ETag = computeETag(t.Data)
+	}
+	return t, nil
+}
+
+// decodeLegacyThumbnailBinary decodes the original format.
+// Layout: [4 bytes: content-type length (big-endian)][content-type bytes][image data]
+func decodeLegacyThumbnailBinary(data []byte) (*ThumbnailResult, error) {
+	ctLen := binary.BigEndian.Uint32(data)
 	if len(data) < 4+int(ctLen) {
 		return nil, fmt.Errorf("invalid binary thumbnail format: content type truncated")
 	}
 
+	imageData := data[4+ctLen:]
 	return &ThumbnailResult{
 		ContentType: string(data[4 : 4+ctLen]),
-		Data:        data[4+ctLen:],
+		Data:        imageData,
+		ETag:        computeETag(imageData),
 	}, nil
 }
 
@@ -0,0 +1,172 @@
+package parser
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sashko-guz/mage/internal/imaging/operations"
+	"lukechampine.com/blake3"
+)
+
+const (
+	presetSizePrefix   = "p:"
+	presetFilterPrefix = "preset("
+)
+
+// Preset is a named size and filter list loaded from the presets file.
+type Preset struct {
+	Size    string `json:"size"`    // Size segment, e.g. "400x300"
+	Filters string `json:"filters"` // Filter list without prefix, e.g. "fmt(webp);q(85)"
+}
+
+// presets holds named presets - loaded once at startup via LoadPresets
+var presets map[string]Preset
+
+// LoadPresets reads named presets from a JSON file mapping names to presets:
+//
+//	{"card": {"size": "400x300", "filters": "fmt(webp);q(85);fit(fill,white)"}}
+//
+// Every preset is validated against the operation registry, so Init must be called first.
+// An empty path disables presets.
+func LoadPresets(path string) (int, error) {
+	if path == "" {
+		presets = nil
+		return 0, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read presets file: %w", err)
+	}
+
+	var loaded map[string]Preset
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return 0, fmt.Errorf("failed to parse presets file: %w", err)
+	}
+
+	for name, preset := range loaded {
+		if err := validatePreset(name, preset); err != nil {
+			return 0, err
+		}
+	}
+
+	presets = loaded
+	return len(presets), nil
+}
+
+// validatePreset checks the preset name, size and filters so broken presets fail at startup
+// instead of on the first request.
+func validatePreset(name string, preset Preset) error {
+	if !isValidPresetName(name) {
+		return fmt.Errorf("invalid preset name %q: only letters, digits, '-' and '_' are allowed", name)
+	}
+
+	if preset.Size != "" {
+		resizeOp := operationRegistry.ResizeOp().Clone().(*operations.ResizeOperation)
+		if err := resizeOp.ParseSize(preset.Size); err != nil {
+			return fmt.Errorf("preset %q: %w", name, err)
+		}
+		if err := resizeOp.Validate(); err != nil {
+			return fmt.Errorf("preset %q: %w", name, err)
+		}
+	}
+
+	req := &operations.Request{}
+	if err := parseFilters(preset.Filters, req); err != nil {
+		return fmt.Errorf("preset %q: %w", name, err)
+	}
+	if req.Preset != "" {
+		return fmt.Errorf("preset %q: presets cannot reference other presets", name)
+	}
+
+	// URLs may choose another output format, so format(auto) stands in for it and
+	// only the checks that do not depend on the format apply here
+	for _, op := range req.Operations {
+		if dependent, ok := op.(operations.FormatDependent); ok {
+			dependent.SetOutputFormat(operations.FormatAuto)
+		}
+	}
+	if err := validateOperations(req.Operations); err != nil {
+		return fmt.Errorf("preset %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// hash identifies the preset definition. It is part of thumbnail cache keys, so cached
+// thumbnails are not served after the preset is edited.
+func (p Preset) hash() string {
+	sum := blake3.Sum256([]byte(p.Size + "\x00" + p.Filters))
+	return hex.EncodeToString(sum[:8])
+}
+
+func isValidPresetName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if !((c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// isPresetSegment reports whether a size segment refers to a preset (p:{name})
+func isPresetSegment(segment string) bool {
+	return strings.HasPrefix(segment, presetSizePrefix)
+}
+
+// lookupPreset returns the preset with the given name
+func lookupPreset(name string) (Preset, error) {
+	preset, ok := presets[name]
+	if !ok {
+		return Preset{}, fmt.Errorf("unknown preset: %s", name)
+	}
+	return preset, nil
+}
+
+// parsePresetFilter extracts the preset name from a preset(name) filter
+func parsePresetFilter(filter string) (string, error) {
+	if !strings.HasSuffix(filter, ")") {
+		return "", fmt.Errorf("preset filter missing closing parenthesis")
+	}
+
+	name := strings.TrimSpace(filter[len(presetFilterPrefix) : len(filter)-1])
+	if name == "" {
+		return "", fmt.Errorf("preset filter requires a preset name")
+	}
+	return name, nil
+}
+
+// applyPresetFilters parses the preset's filters and merges them into the request.
+// Operations given explicitly in the URL take precedence over preset operations of the same type.
+func applyPresetFilters(req *operations.Request, preset Preset) error {
+	presetReq := &operations.Request{}
+	if err := parseFilters(preset.Filters, presetReq); err != nil {
+		return fmt.Errorf("preset %s: %w", req.Preset, err)
+	}
+
+	merged := make([]operations.Operation, 0, len(presetReq.Operations)+len(req.Operations))
+	for _, op := range req.Operations {
+		if _, ok := op.(*operations.ResizeOperation); ok {
+			merged = append(merged, op)
+		}
+	}
+	for _, op := range presetReq.Operations {
+		if !hasOperation(req, op.Name()) {
+			merged = append(merged, op)
+		}
+	}
+	for _, op := range req.Operations {
+		if _, ok := op.(*operations.ResizeOperation); !ok {
+			merged = append(merged, op)
+		}
+	}
+
+	req.Operations = merged
+	return nil
+}
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/sashko-guz/mage/internal/imaging/operations"
@@ -12,12 +13,14 @@ var (
 	operationRegistry          *operations.Registry
 	signatureLength            = 16
 	signatureValidationEnabled = true
+	defaultFormat              = ""
+	defaultMetadata            *operations.MetadataOperation
 )
 
-// Init initializes the parser with resize dimension limits from config.
+// Init initializes the parser with resize dimension and animation limits from config.
 // Must be called once at startup before any ParseURL calls.
-func Init(maxWidth, maxHeight, maxResolution int) {
-	operationRegistry = operations.NewRegistry(maxWidth, maxHeight, maxResolution)
+func Init(maxWidth, maxHeight, maxResolution, maxFrames, maxAnimationResolution int) {
+	operationRegistry = operations.NewRegistry(maxWidth, maxHeight, maxResolution, maxFrames, maxAnimationResolution)
 }
 
 // SetSignatureLength configures expected signature length in URL.
@@ -32,24 +35,70 @@ func SetSignatureValidationEnabled(enabled bool) {
 	signatureValidationEnabled = enabled
 }
 
+// SetDefaultFormat configures the output format used when neither a format filter
+// nor an alias extension is present. Accepts "auto" or a supported format name;
+// an empty value keeps detection from the source path extension.
+func SetDefaultFormat(format string) error {
+	format = normalizeFormatName(format)
+	switch format {
+	case "", operations.FormatAuto, "jpeg", "png", "webp", "avif", "jxl", "gif", "tiff", "heic":
+		defaultFormat = format
+		return nil
+	default:
+		return fmt.Errorf("unsupported default format: %s (supported: auto, jpeg, png, webp, avif, jxl, gif, tiff, heic)", format)
+	}
+}
+
+// SetDefaultMetadata configures the metadata kept when the URL has no strip()/keep() filter.
+// Accepts a comma-separated list of none, icc, exif, xmp, iptc, all; an empty value keeps
+// the libvips save defaults.
+func SetDefaultMetadata(value string) error {
+	if strings.TrimSpace(value) == "" {
+		defaultMetadata = nil
+		return nil
+	}
+
+	keep, err := operations.ParseMetadataKeep(strings.Split(value, ","))
+	if err != nil {
+		return err
+	}
+	defaultMetadata = &operations.MetadataOperation{Keep: keep}
+	return nil
+}
+
+// SetDefaultEncoding configures the encoder tuning used when the URL has no encoder filters
+// (progressive, optimize, subsampling, effort, lossless, palette).
+func SetDefaultEncoding(options operations.EncodeOptions) error {
+	if err := options.Validate(); err != nil {
+		return err
+	}
+	operationRegistry.FormatOp().Encoding = options
+	return nil
+}
+
 // ParseURL parses a URL path and returns a Request with parsed operations
 //
 // URL Format (prefix already stripped by router):
 //   - With signature: /{signature}/{size}/[filters:{filters}/]{path}[/as/{alias.ext}]
 //   - Without signature: /{size}/[filters:{filters}/]{path}[/as/{alias.ext}]
+//   - {size} may be p:{name} to take size and filters from a named preset
 //
 // Examples:
 //   - /200x350/filters:format(webp);quality(90)/image.jpg
 //   - /abc123/200x300/filters:crop(10,10,500,500);fit(cover)/image.jpg
 //   - /200x350/path/to/source.jpg/as/preview.avif
+//   - /p:card/photos/cat.jpg
+//   - /200x350/filters:preset(card);quality(60)/image.jpg
 //
 // Operation Rules:
 //   - Only ONE operation of each type is allowed per request
 //   - Default values are automatically applied for missing operations:
-//     1. format: detected from alias extension first (if present), then from source path extension, fallback to "jpeg"
+//     1. format: detected from alias extension first (if present), then the server default format
+//     (if configured), then from source path extension, fallback to "jpeg"
 //     2. quality: 75
 //     3. resize: fit="cover", fillColor="white"
 //     4. crop and fit operations are optional
+//     5. metadata: the server default (if configured), otherwise libvips save defaults
 func ParseURL(path string) (*operations.Request, error) {
 	// Remove leading slash
 	path = strings.TrimPrefix(path, "/")
@@ -80,7 +129,7 @@ func ParseURL(path string) (*operations.Request, error) {
 	// Check if parts[0] looks like a valid signature (correct length and valid base64 RawURL chars)
 	// AND verify that parts[1] is a valid size format
 	// This takes priority over size format check since signatures can contain 'x'
-	if len(parts[0]) == signatureLength && looksLikeBase64Signature(parts[0]) && len(parts) >= 3 && resizePrototype.IsSizeFormat(parts[1]) {
+	if len(parts[0]) == signatureLength && !isPresetSegment(parts[0]) && looksLikeBase64Signature(parts[0]) && len(parts) >= 3 && isSizeSegment(parts[1]) {
 		// Has signature
 		sizeIndex = 1
 		req.ProvidedSignature = parts[0]
@@ -104,8 +153,20 @@ func ParseURL(path string) (*operations.Request, error) {
 	// Clone resize operation for this request
 	resizeOp := resizePrototype.Clone().(*operations.ResizeOperation)
 
-	// Parse size
-	if err := resizeOp.ParseSize(parts[sizeIndex]); err != nil {
+	// Parse size, either explicit or taken from a p:{name} preset
+	sizeSegment := parts[sizeIndex]
+	if isPresetSegment(sizeSegment) {
+		req.Preset = strings.TrimPrefix(sizeSegment, presetSizePrefix)
+		preset, err := lookupPreset(req.Preset)
+		if err != nil {
+			return nil, err
+		}
+		if preset.Size == "" {
+			return nil, fmt.Errorf("preset %s has no size and cannot be used as a size segment", req.Preset)
+		}
+		sizeSegment = preset.Size
+	}
+	if err := resizeOp.ParseSize(sizeSegment); err != nil {
 		return nil, err
 	}
 
@@ -141,6 +202,18 @@ func ParseURL(path string) (*operations.Request, error) {
 		req.FilterString = ""
 	}
 
+	// Merge preset filters; filters given in the URL override preset filters of the same type
+	if req.Preset != "" {
+		preset, err := lookupPreset(req.Preset)
+		if err != nil {
+			return nil, err
+		}
+		if err := applyPresetFilters(req, preset); err != nil {
+			return nil, err
+		}
+		req.PresetHash = preset.hash()
+	}
+
 	// Parse optional alias suffix: {path}/as/{alias.ext}
 	sourcePath, aliasName, aliasFormat, hasAlias, err := parseAliasSuffix(filePath)
 	if err != nil {
@@ -154,9 +227,14 @@ func ParseURL(path string) (*operations.Request, error) {
 	// Ensure format operation is present (either from filters or from extension)
 	if !hasOperation(req, "format") {
 		formatOp := operationRegistry.FormatOp().Clone().(*operations.FormatOperation)
-		if req.HasAlias && req.AliasExtension != "" {
+		switch {
+		case req.HasAlias && req.AliasExtension != "":
 			formatOp.Format = req.AliasExtension
-		} else {
+		case defaultFormat == operations.FormatAuto:
+			formatOp.SetAuto()
+		case defaultFormat != "":
+			formatOp.Format = defaultFormat
+		default:
 			formatOp.DetectFromExtension(req.Path)
 		}
 		req.Operations = append(req.Operations, formatOp)
@@ -178,9 +256,28 @@ func ParseURL(path string) (*operations.Request, error) {
 		req.Operations = append(req.Operations, qualityOp)
 	}
 
+	// Apply the server metadata policy unless the URL sets strip() or keep()
+	if defaultMetadata != nil && !hasOperation(req, "metadata") {
+		req.Operations = append(req.Operations, &operations.MetadataOperation{Keep: slices.Clone(defaultMetadata.Keep)})
+	}
+
 	// Apply fit mode from FitOperation to ResizeOperation if present
 	applyFitModeToResize(req)
 
+	// Scale resize dimensions by the device pixel ratio before size limits are validated
+	if err := applyDPRToResize(req); err != nil {
+		return nil, err
+	}
+
+	// Let border follow the radius or circle mask if present
+	applyMaskToBorder(req)
+
+	// Let format-dependent operations know the output format before validation
+	applyOutputFormat(req)
+
+	// Apply encoder filters over the server encoding defaults
+	applyEncoderOptions(req)
+
 	// Run per-operation validation hooks
 	if err := validateOperations(req.Operations); err != nil {
 		return nil, err
@@ -191,6 +288,21 @@ func ParseURL(path string) (*operations.Request, error) {
 		return nil, err
 	}
 
+	// Validate focal, pfocal and gravity are not used together
+	if err := validateFocalExclusivity(req); err != nil {
+		return nil, err
+	}
+
+	// Validate radius and circle are not used together
+	if err := validateMaskExclusivity(req); err != nil {
+		return nil, err
+	}
+
+	// Validate animated is not combined with operations that crop frames differently
+	if err := validateAnimatedCompatibility(req); err != nil {
+		return nil, err
+	}
+
 	return req, nil
 }
 
@@ -205,7 +317,7 @@ func parseAliasSuffix(path string) (sourcePath, aliasName, aliasFormat string, h
 
 		aliasFormat = detectKnownFormat(aliasName)
 		if aliasFormat == "" {
-			return "", "", "", false, fmt.Errorf("alias must include a supported extension (.jpg, .jpeg, .png, .webp, .avif)")
+			return "", "", "", false, fmt.Errorf("alias must include a supported extension (.jpg, .jpeg, .png, .webp, .avif, .jxl, .gif, .tif, .tiff, .heic, .heif)")
 		}
 
 		sourcePath = strings.Join(parts[:len(parts)-2], "/")
@@ -230,6 +342,14 @@ func detectKnownFormat(path string) string {
 		return "png"
 	case strings.HasSuffix(ext, ".jpg"), strings.HasSuffix(ext, ".jpeg"):
 		return "jpeg"
+	case strings.HasSuffix(ext, ".jxl"):
+		return "jxl"
+	case strings.HasSuffix(ext, ".gif"):
+		return "gif"
+	case strings.HasSuffix(ext, ".tif"), strings.HasSuffix(ext, ".tiff"):
+		return "tiff"
+	case strings.HasSuffix(ext, ".heic"), strings.HasSuffix(ext, ".heif"):
+		return "heic"
 	default:
 		return ""
 	}
@@ -237,8 +357,11 @@ func detectKnownFormat(path string) string {
 
 func normalizeFormatName(format string) string {
 	format = strings.ToLower(strings.TrimSpace(format))
-	if format == "jpg" {
+	switch format {
+	case "jpg":
 		return "jpeg"
+	case "tif":
+		return "tiff"
 	}
 	return format
 }
@@ -265,6 +388,19 @@ func parseFilters(filterString string, req *operations.Request) error {
 			continue
 		}
 
+		// preset(name) is expanded after all URL filters are parsed
+		if strings.HasPrefix(filter, presetFilterPrefix) {
+			name, err := parsePresetFilter(filter)
+			if err != nil {
+				return err
+			}
+			if req.Preset != "" {
+				return fmt.Errorf("only one preset allowed per request, got %s and %s", req.Preset, name)
+			}
+			req.Preset = name
+			continue
+		}
+
 		// Parse the filter using registry
 		op, err := operationRegistry.ParseFilter(filter)
 		if err != nil {
@@ -285,6 +421,11 @@ func parseFilters(filterString string, req *operations.Request) error {
 	return nil
 }
 
+// isSizeSegment reports whether a path segment is a size ({width}x{height}) or a preset (p:{name})
+func isSizeSegment(segment string) bool {
+	return operationRegistry.ResizeOp().IsSizeFormat(segment) || isPresetSegment(segment)
+}
+
 // hasOperation checks if an operation with the given name exists in the request
 func hasOperation(req *operations.Request, name string) bool {
 	for _, op := range req.Operations {
@@ -295,21 +436,27 @@ func hasOperation(req *operations.Request, name string) bool {
 	return false
 }
 
-// applyFitModeToResize updates ResizeOperation's Fit mode based on FitOperation if present
+// applyFitModeToResize updates ResizeOperation's Fit mode, Gravity and Focal point based on
+// FitOperation, GravityOperation and FocalOperation/PercentFocalOperation if present
 func applyFitModeToResize(req *operations.Request) {
 	var resizeOp *operations.ResizeOperation
 	var fitOp *operations.FitOperation
-	var formatOp *operations.FormatOperation
+	var gravityOp *operations.GravityOperation
+	var focal *operations.FocalPoint
 
-	// Find resize, fit, and format operations
+	// Find resize, fit, gravity and focal operations
 	for _, op := range req.Operations {
 		switch v := op.(type) {
 		case *operations.ResizeOperation:
 			resizeOp = v
 		case *operations.FitOperation:
 			fitOp = v
-		case *operations.FormatOperation:
-			formatOp = v
+		case *operations.GravityOperation:
+			gravityOp = v
+		case *operations.FocalOperation:
+			focal = v.FocalPoint()
+		case *operations.PercentFocalOperation:
+			focal = v.FocalPoint()
 		}
 	}
 
@@ -317,11 +464,96 @@ func applyFitModeToResize(req *operations.Request) {
 	if resizeOp != nil && fitOp != nil {
 		resizeOp.Fit = fitOp.Mode
 		resizeOp.FillColor = fitOp.FillColor
+		resizeOp.NoUpscale = fitOp.NoUpscale
+	}
+
+	// If both resize and gravity exist, apply crop gravity to resize
+	if resizeOp != nil && gravityOp != nil {
+		resizeOp.Gravity = gravityOp.Gravity
+	}
+	// If both resize and a focal point exist, apply focal point to resize
+	if resizeOp != nil && focal != nil {
+		resizeOp.Focal = focal
+	}
+}
+
+// applyDPRToResize multiplies the requested size by dpr(...) if present.
+// Scaled sizes beyond the resize limits are rejected, like sizes requested directly.
+func applyDPRToResize(req *operations.Request) error {
+	var resizeOp *operations.ResizeOperation
+	var dprOp *operations.DPROperation
+
+	for _, op := range req.Operations {
+		switch v := op.(type) {
+		case *operations.ResizeOperation:
+			resizeOp = v
+		case *operations.DPROperation:
+			dprOp = v
+		}
+	}
+
+	if resizeOp == nil || dprOp == nil {
+		return nil
+	}
+	if err := dprOp.Validate(); err != nil {
+		return err
+	}
+
+	dprOp.Scale(resizeOp)
+	if err := resizeOp.Validate(); err != nil {
+		return fmt.Errorf("dpr(%g): %w", dprOp.Ratio, err)
+	}
+	return nil
+}
+
+// applyMaskToBorder passes the radius()/circle() outline to border() if both are present
+func applyMaskToBorder(req *operations.Request) {
+	var borderOp *operations.BorderOperation
+	var shape *operations.MaskShape
+
+	for _, op := range req.Operations {
+		switch v := op.(type) {
+		case *operations.BorderOperation:
+			borderOp = v
+		case *operations.RadiusOperation:
+			s := v.Shape()
+			shape = &s
+		case *operations.CircleOperation:
+			s := v.Shape()
+			shape = &s
+		}
 	}
 
-	// If fit and format exist, set format on fit for validation
-	if fitOp != nil && formatOp != nil {
-		fitOp.Format = formatOp.Format
+	if borderOp != nil && shape != nil {
+		borderOp.Shape = *shape
+	}
+}
+
+// applyOutputFormat passes the output format to operations that validate against it
+func applyOutputFormat(req *operations.Request) {
+	formatOp := getFormatOperation(req)
+	if formatOp == nil {
+		return
+	}
+
+	for _, op := range req.Operations {
+		if dependent, ok := op.(operations.FormatDependent); ok {
+			dependent.SetOutputFormat(formatOp.Format)
+		}
+	}
+}
+
+// applyEncoderOptions lets encoder filters override the encoding defaults of the format operation
+func applyEncoderOptions(req *operations.Request) {
+	formatOp := getFormatOperation(req)
+	if formatOp == nil {
+		return
+	}
+
+	for _, op := range req.Operations {
+		if encoderOption, ok := op.(operations.EncoderOption); ok {
+			encoderOption.ApplyEncoding(&formatOp.Encoding)
+		}
 	}
 }
 
@@ -346,6 +578,66 @@ func validateCropExclusivity(req *operations.Request) error {
 	return nil
 }
 
+// validateFocalExclusivity checks that only one of focal, pfocal and gravity is used
+func validateFocalExclusivity(req *operations.Request) error {
+	var used []string
+
+	for _, op := range req.Operations {
+		switch op.(type) {
+		case *operations.FocalOperation, *operations.PercentFocalOperation, *operations.GravityOperation:
+			used = append(used, op.Name())
+		}
+	}
+
+	if len(used) > 1 {
+		return fmt.Errorf("cannot use %s operations in the same request (use only one of focal, pfocal or gravity)", strings.Join(used, " and "))
+	}
+
+	return nil
+}
+
+// validateMaskExclusivity checks that radius and circle are not used together
+func validateMaskExclusivity(req *operations.Request) error {
+	var hasRadius bool
+	var hasCircle bool
+
+	for _, op := range req.Operations {
+		switch op.(type) {
+		case *operations.RadiusOperation:
+			hasRadius = true
+		case *operations.CircleOperation:
+			hasCircle = true
+		}
+	}
+
+	if hasRadius && hasCircle {
+		return fmt.Errorf("cannot use both radius and circle operations in the same request (use either radius or circle, not both)")
+	}
+
+	return nil
+}
+
+// validateAnimatedCompatibility rejects trim and smart gravity with animated(), as they pick a
+// different crop for each frame and the frames would no longer line up.
+func validateAnimatedCompatibility(req *operations.Request) error {
+	if !hasOperation(req, "animated") {
+		return nil
+	}
+
+	for _, op := range req.Operations {
+		switch v := op.(type) {
+		case *operations.TrimOperation:
+			return fmt.Errorf("cannot use trim with animated (frames would be trimmed differently)")
+		case *operations.GravityOperation:
+			if v.Gravity == operations.GravityAttention || v.Gravity == operations.GravityEntropy {
+				return fmt.Errorf("cannot use gravity(%s) with animated (frames would be cropped differently)", v.Gravity)
+			}
+		}
+	}
+
+	return nil
+}
+
 func validateOperations(ops []operations.Operation) error {
 	for _, op := range ops {
 		if validatable, ok := op.(operations.Validatable); ok {
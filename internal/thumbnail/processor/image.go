@@ -1,15 +1,26 @@
 package processor
 
 import (
+	"context"
+
 	"github.com/sashko-guz/mage/internal/imaging/operations"
 )
 
-type ImageProcessor struct{}
+type ImageProcessor struct {
+	resources operations.ResourceLoader // Source of overlay images (watermarks)
+}
+
+func NewImageProcessor(resources operations.ResourceLoader) *ImageProcessor {
+	return &ImageProcessor{
+		resources: resources,
+	}
+}
 
-func NewImageProcessor() *ImageProcessor {
-	return &ImageProcessor{}
+func (p *ImageProcessor) CreateThumbnail(ctx context.Context, imageData []byte, req *operations.Request) ([]byte, string, error) {
+	return operations.ApplyAll(ctx, imageData, req, p.resources)
 }
 
-func (p *ImageProcessor) CreateThumbnail(imageData []byte, req *operations.Request) ([]byte, string, error) {
-	return operations.ApplyAll(imageData, req)
+// Describe reports the source image properties and the output the request would produce
+func (p *ImageProcessor) Describe(ctx context.Context, imageData []byte, req *operations.Request) (*operations.ImageInfo, error) {
+	return operations.Describe(ctx, imageData, req)
 }